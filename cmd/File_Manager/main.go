@@ -1,10 +1,16 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Protheophage/GO/internal/config"
 	"github.com/Protheophage/GO/pkg/file_manipulation"
 )
 
@@ -18,6 +24,8 @@ func main() {
 		fmt.Println("  count      Count files matching a pattern")
 		fmt.Println("    Flags:")
 		fmt.Println("      -pattern: File pattern to count (e.g., '*.txt').")
+		fmt.Println("      -use-index: Answer from the file index instead of walking the disk.")
+		fmt.Println("      -index: Index file to use (default: per-user cache directory).")
 		fmt.Println("      -all: Search all drives (default: false).")
 		fmt.Println("      -disk: Specify a disk to search.")
 		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
@@ -26,6 +34,9 @@ func main() {
 		fmt.Println("  remove     Remove files matching a pattern")
 		fmt.Println("    Flags:")
 		fmt.Println("      -pattern: File pattern to remove (e.g., '*.log').")
+		fmt.Println("      -shred: Overwrite file contents before removing them (default: false).")
+		fmt.Println("      -passes: Number of overwrite passes when shredding (default: 3).")
+		fmt.Println("      -fill: Overwrite with 'random' data or 'zeros' when shredding (default: random).")
 		fmt.Println("      -all: Search all drives (default: false).")
 		fmt.Println("      -disk: Specify a disk to search.")
 		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
@@ -34,6 +45,9 @@ func main() {
 		fmt.Println("  find       Find files matching a pattern")
 		fmt.Println("    Flags:")
 		fmt.Println("      -pattern: File pattern to find (e.g., '*.go').")
+		fmt.Println("      -detected-type: Only list files whose content is of this type (e.g., 'PE', 'ELF').")
+		fmt.Println("      -use-index: Answer from the file index instead of walking the disk.")
+		fmt.Println("      -index: Index file to use (default: per-user cache directory).")
 		fmt.Println("      -all: Search all drives (default: false).")
 		fmt.Println("      -disk: Specify a disk to search.")
 		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
@@ -44,6 +58,9 @@ func main() {
 		fmt.Println("      -string: String to search for in files (e.g., 'TODO').")
 		fmt.Println("      -type: File type to search (e.g., '.go').")
 		fmt.Println("      -maxsize: Max file size in KB (default: 1024).")
+		fmt.Println("      -offsets: List every match with its encoding, line, byte offset and character offset.")
+		fmt.Println("      -binary: Search the raw bytes of all files, including binary files, in several encodings.")
+		fmt.Println("      -encodings: Comma-separated encodings for -binary (default: utf-8,utf-16le,utf-16be).")
 		fmt.Println("      -all: Search all drives (default: false).")
 		fmt.Println("      -disk: Specify a disk to search.")
 		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
@@ -58,6 +75,174 @@ func main() {
 		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
 		fmt.Println("             Linux: '/' or '/home/user/'")
 		fmt.Println()
+		fmt.Println("  filetype   Detect file types from content and flag extension mismatches")
+		fmt.Println("    Flags:")
+		fmt.Println("      -pattern: File pattern to inspect (e.g., '*.jpg').")
+		fmt.Println("      -mismatch-only: Only list files whose extension does not match their content.")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  sync       Mirror a source directory into a destination directory")
+		fmt.Println("    Usage: file-manager sync [flags] <source> <destination>")
+		fmt.Println("    Flags:")
+		fmt.Println("      -hash: Compare files by SHA-256 instead of size and modification time.")
+		fmt.Println("      -delete: Delete destination files that do not exist in the source.")
+		fmt.Println("      -dry-run: List adds, updates and deletes without changing anything.")
+		fmt.Println("      -include: Comma-separated patterns of files to sync (e.g., '*.conf,*.json').")
+		fmt.Println("      -exclude: Comma-separated patterns of files or directories to skip.")
+		fmt.Println()
+		fmt.Println("  index      Build, update or query the persistent file index")
+		fmt.Println("    Usage: file-manager index build|update|query [flags]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -index: Index file to use (default: per-user cache directory).")
+		fmt.Println("      -hash: Record SHA-256 hashes when building (default: false).")
+		fmt.Println("      -pattern: File name pattern to query (e.g., '*.log').")
+		fmt.Println("      -min-size / -max-size: Size range to query, in bytes.")
+		fmt.Println("      -newer: Only return files modified within this duration (e.g., '24h').")
+		fmt.Println("      -all: Index all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to index or query.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  snapshot   Create a baseline snapshot of a tree or diff against one")
+		fmt.Println("    Usage: file-manager snapshot create|diff [flags]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -disk: Directory to snapshot, or to compare against the baseline.")
+		fmt.Println("      -out: Snapshot file to write (create).")
+		fmt.Println("      -baseline: Baseline snapshot file (diff).")
+		fmt.Println("      -against: Second snapshot file to compare instead of the live tree (diff).")
+		fmt.Println("      -json: Print the diff as JSON (default: false).")
+		fmt.Println()
+		fmt.Println("  prune      Remove files according to a retention policy")
+		fmt.Println("    Flags:")
+		fmt.Println("      -pattern: Comma-separated file patterns the policy applies to (e.g., '*.log').")
+		fmt.Println("      -max-age: Remove files older than this (e.g., '14d', '36h').")
+		fmt.Println("      -keep-last: Always keep the newest N files in each group.")
+		fmt.Println("      -max-size: Trim each group to this total size, oldest first (e.g., '20GB').")
+		fmt.Println("      -group-by: Apply the rules per 'directory' or per 'pattern' (default: directory).")
+		fmt.Println("      -dry-run: Show the plan without removing anything.")
+		fmt.Println("      -audit: Audit log to append removals to (default: per-user cache directory).")
+		fmt.Println("      -disk: Directory to prune.")
+		fmt.Println()
+		fmt.Println("  collect    Package matching files into a hashed evidence archive")
+		fmt.Println("    Flags:")
+		fmt.Println("      -pattern: File pattern to collect (e.g., '*.sh').")
+		fmt.Println("      -out: Archive to create, '.zip' or '.tar.gz' (default: evidence.tar.gz).")
+		fmt.Println("      -sign-key: PEM ed25519 private key used to sign the manifest.")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  verify     Verify an evidence archive against its manifest")
+		fmt.Println("    Flags:")
+		fmt.Println("      -archive: Evidence archive to verify.")
+		fmt.Println("      -pubkey: PEM ed25519 public key used to check the manifest signature.")
+		fmt.Println()
+		fmt.Println("  empty-dirs   Find empty directories, bottom-up")
+		fmt.Println("    Flags:")
+		fmt.Println("      -remove: Remove the empty directories, cascading up to emptied parents (default: false).")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  broken-links Find dangling and looping symbolic links")
+		fmt.Println("    Flags:")
+		fmt.Println("      -remove: Remove dangling and looping links (default: false).")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  perms-audit Flag risky permissions, SUID/SGID binaries and orphaned owners (Linux and macOS)")
+		fmt.Println("    Flags:")
+		fmt.Println("      -allowlist: File listing expected SUID/SGID binaries; others are reported as critical.")
+		fmt.Println("      -update-allowlist: Write the SUID/SGID binaries found to the -allowlist file (default: false).")
+		fmt.Println("      -json: Print the findings as JSON (default: false).")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  bodyfile   Write a Sleuth Kit bodyfile for a directory tree")
+		fmt.Println("    Flags:")
+		fmt.Println("      -out: Bodyfile to write (default: bodyfile.txt).")
+		fmt.Println("      -md5: Hash regular files with MD5 (default: false).")
+		fmt.Println("      -include: Comma-separated patterns of files to include.")
+		fmt.Println("      -exclude: Comma-separated patterns of files and directories to skip.")
+		fmt.Println("      -disk: Directory to walk.")
+		fmt.Println()
+		fmt.Println("  timeline   Build a sorted MACB timeline from bodyfiles")
+		fmt.Println("    Usage: file-manager timeline [flags] <bodyfile>...")
+		fmt.Println("    Flags:")
+		fmt.Println("      -start: Only events at or after this time (e.g., '2024-03-01', RFC 3339, or an age such as '7d').")
+		fmt.Println("      -end: Only events before this time.")
+		fmt.Println("      -pattern: Only files whose name or path matches this pattern (e.g., '*.sh').")
+		fmt.Println("      -type: Only events with one of these MACB letters (e.g., 'mb').")
+		fmt.Println("      -format: Output format, 'csv' or 'json' (default: csv).")
+		fmt.Println("      -out: File to write the timeline to (default: standard output).")
+		fmt.Println()
+		fmt.Println("  entropy    List files whose contents look encrypted or packed")
+		fmt.Println("    Flags:")
+		fmt.Println("      -pattern: File pattern to analyze (default: '*').")
+		fmt.Println("      -min: Minimum Shannon entropy in bits per byte (default: 7.5).")
+		fmt.Println("      -sample: Read this many evenly spaced 64 KB blocks instead of the whole file (default: 0, whole file).")
+		fmt.Println("      -min-size: Skip files smaller than this (default: 1KB).")
+		fmt.Println("      -include-compressed: Also report formats compressed by nature, such as zip, jpg and gz (default: false).")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  secrets    Scan files for leaked keys, tokens and passwords")
+		fmt.Println("    Flags:")
+		fmt.Println("      -pattern: Comma-separated patterns of files to scan (default: all files).")
+		fmt.Println("      -rules: JSON file with custom rules and allowlists.")
+		fmt.Println("      -max-size: Skip files larger than this (default: 10MB).")
+		fmt.Println("      -show-secrets: Print secrets in full instead of redacted (default: false).")
+		fmt.Println("      -list-rules: List the enabled rules and exit (default: false).")
+		fmt.Println("      -json: Print the findings as JSON (default: false).")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  replace    Search and replace text across files, with a diff preview")
+		fmt.Println("    Flags:")
+		fmt.Println("      -pattern: File pattern to edit (e.g., '*.conf').")
+		fmt.Println("      -string: The literal string or regular expression to replace.")
+		fmt.Println("      -replacement: The replacement text; with -regex, $1 and ${name} expand to capture groups.")
+		fmt.Println("      -regex: Treat -string as a regular expression (default: false).")
+		fmt.Println("      -backup: Keep the original contents as <file>.bak (default: false).")
+		fmt.Println("      -dry-run: Show the diff without writing anything (default: false).")
+		fmt.Println("      -yes: Apply the changes without asking for confirmation (default: false).")
+		fmt.Println("      -max-size: Skip files larger than this (default: 10MB).")
+		fmt.Println("      -all: Search all drives (default: false).")
+		fmt.Println("      -disk: Specify a disk to search.")
+		fmt.Println("             Windows: 'C:\\' or 'D:\\'")
+		fmt.Println("             Linux: '/' or '/home/user/'")
+		fmt.Println()
+		fmt.Println("  config     Show the effective configuration")
+		fmt.Println("    Usage: file-manager config show [flags]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -command: Show the effective flag values for this command.")
+		fmt.Println("      -profile: Apply this profile when showing the effective values.")
+		fmt.Println()
+		fmt.Println("Every command accepts -profile=<name> to load flag values from a named profile.")
+		fmt.Println("Every command also accepts these flags to limit its load on a busy server:")
+		fmt.Println("  -max-read-mbps: Cap file reads at this many MB/s (default: 0, unlimited).")
+		fmt.Println("  -max-files-per-sec: Cap the number of files visited per second (default: 0, unlimited).")
+		fmt.Println("  -low-impact: Lower the process CPU and IO priority and apply default caps of 10 MB/s and 200 files/s (default: false).")
+		fmt.Println("Config files (JSON) are read from the system path, then the per-user path:")
+		fmt.Println("  System: " + config.SystemConfigPath())
+		fmt.Println("  User:   " + config.UserConfigPath())
+		fmt.Println("Command-line flags override profile values, which override configured defaults.")
+		fmt.Println("The global \"defaults\" section may only set -max-read-mbps, -max-files-per-sec, -low-impact, -maxsize and -max-size;")
+		fmt.Println("destructive flags (e.g. -disk, -all, -yes, -delete, -remove) must be set under \"commands\" or in a profile.")
+		fmt.Println()
 		fmt.Println("Use 'file-manager <command> -h' for more information about a command.")
 		os.Exit(0)
 	}
@@ -68,26 +253,53 @@ func main() {
 	findCmd := flag.NewFlagSet("find", flag.ExitOnError)
 	contentCmd := flag.NewFlagSet("content", flag.ExitOnError)
 	extensionCmd := flag.NewFlagSet("extension", flag.ExitOnError)
+	syncCmd := flag.NewFlagSet("sync", flag.ExitOnError)
+	filetypeCmd := flag.NewFlagSet("filetype", flag.ExitOnError)
+	indexCmd := flag.NewFlagSet("index", flag.ExitOnError)
+	snapshotCmd := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	pruneCmd := flag.NewFlagSet("prune", flag.ExitOnError)
+	collectCmd := flag.NewFlagSet("collect", flag.ExitOnError)
+	verifyCmd := flag.NewFlagSet("verify", flag.ExitOnError)
+	emptyDirsCmd := flag.NewFlagSet("empty-dirs", flag.ExitOnError)
+	brokenLinksCmd := flag.NewFlagSet("broken-links", flag.ExitOnError)
+	permsAuditCmd := flag.NewFlagSet("perms-audit", flag.ExitOnError)
+	bodyfileCmd := flag.NewFlagSet("bodyfile", flag.ExitOnError)
+	timelineCmd := flag.NewFlagSet("timeline", flag.ExitOnError)
+	entropyCmd := flag.NewFlagSet("entropy", flag.ExitOnError)
+	secretsCmd := flag.NewFlagSet("secrets", flag.ExitOnError)
+	replaceCmd := flag.NewFlagSet("replace", flag.ExitOnError)
+	configCmd := flag.NewFlagSet("config", flag.ExitOnError)
 
 	// Flags for count
 	countPattern := countCmd.String("pattern", "*", "File pattern to count")
 	countAll := countCmd.Bool("all", false, "Search all drives")
 	countDisk := countCmd.String("disk", "", "Specific disk to search")
+	countUseIndex := countCmd.Bool("use-index", false, "Answer from the file index instead of walking the disk")
+	countIndex := countCmd.String("index", "", "Index file to use (default: per-user cache directory)")
 
 	// Flags for remove
 	removePattern := removeCmd.String("pattern", "*", "File pattern to remove")
 	removeAll := removeCmd.Bool("all", false, "Search all drives")
 	removeDisk := removeCmd.String("disk", "", "Specific disk to search")
+	removeShred := removeCmd.Bool("shred", false, "Overwrite file contents before removing them")
+	removePasses := removeCmd.Int("passes", 3, "Number of overwrite passes when shredding")
+	removeFill := removeCmd.String("fill", "random", "Overwrite with 'random' data or 'zeros' when shredding")
 
 	// Flags for find
 	findPattern := findCmd.String("pattern", "*", "File pattern to find")
 	findAll := findCmd.Bool("all", false, "Search all drives")
 	findDisk := findCmd.String("disk", "", "Specific disk to search")
+	findUseIndex := findCmd.Bool("use-index", false, "Answer from the file index instead of walking the disk")
+	findIndex := findCmd.String("index", "", "Index file to use (default: per-user cache directory)")
+	findDetectedType := findCmd.String("detected-type", "", "Only list files whose content is of this type (e.g., PE, ELF, ZIP)")
 
 	// Flags for content
 	contentString := contentCmd.String("string", "", "String to search for in files")
 	contentType := contentCmd.String("type", "", "File type to search")
 	contentMaxSize := contentCmd.Int("maxsize", 1024, "Max file size in KB")
+	contentOffsets := contentCmd.Bool("offsets", false, "List every match with its encoding, line and offsets")
+	contentBinary := contentCmd.Bool("binary", false, "Search the raw bytes of all files in several encodings")
+	contentEncodings := contentCmd.String("encodings", "utf-8,utf-16le,utf-16be", "Comma-separated encodings for -binary")
 	contentAll := contentCmd.Bool("all", false, "Search all drives")
 	contentDisk := contentCmd.String("disk", "", "Specific disk to search")
 
@@ -97,6 +309,162 @@ func main() {
 	extensionAll := extensionCmd.Bool("all", false, "Search all drives")
 	extensionDisk := extensionCmd.String("disk", "", "Specific disk to search")
 
+	// Flags for sync
+	syncHash := syncCmd.Bool("hash", false, "Compare files by SHA-256 instead of size and modification time")
+	syncDelete := syncCmd.Bool("delete", false, "Delete destination files that do not exist in the source")
+	syncDryRun := syncCmd.Bool("dry-run", false, "List changes without applying them")
+	syncInclude := syncCmd.String("include", "", "Comma-separated patterns of files to sync")
+	syncExclude := syncCmd.String("exclude", "", "Comma-separated patterns of files or directories to skip")
+
+	// Flags for filetype
+	filetypePattern := filetypeCmd.String("pattern", "*", "File pattern to inspect")
+	filetypeMismatchOnly := filetypeCmd.Bool("mismatch-only", false, "Only list files whose extension does not match their content")
+	filetypeAll := filetypeCmd.Bool("all", false, "Search all drives")
+	filetypeDisk := filetypeCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for index
+	indexFile := indexCmd.String("index", "", "Index file to use (default: per-user cache directory)")
+	indexHash := indexCmd.Bool("hash", false, "Record SHA-256 hashes when building")
+	indexPattern := indexCmd.String("pattern", "*", "File name pattern to query")
+	indexMinSize := indexCmd.Int64("min-size", 0, "Minimum file size in bytes to query")
+	indexMaxSize := indexCmd.Int64("max-size", 0, "Maximum file size in bytes to query")
+	indexNewer := indexCmd.Duration("newer", 0, "Only return files modified within this duration")
+	indexAll := indexCmd.Bool("all", false, "Index all drives")
+	indexDisk := indexCmd.String("disk", "", "Specific disk to index or query")
+
+	// Flags for snapshot
+	snapshotDisk := snapshotCmd.String("disk", "", "Directory to snapshot or compare against the baseline")
+	snapshotOut := snapshotCmd.String("out", "snapshot.json", "Snapshot file to write")
+	snapshotBaseline := snapshotCmd.String("baseline", "", "Baseline snapshot file to compare against")
+	snapshotAgainst := snapshotCmd.String("against", "", "Second snapshot file to compare instead of the live tree")
+	snapshotJSON := snapshotCmd.Bool("json", false, "Print the diff as JSON")
+
+	// Flags for prune
+	prunePattern := pruneCmd.String("pattern", "", "Comma-separated file patterns the policy applies to")
+	pruneMaxAge := pruneCmd.String("max-age", "", "Remove files older than this (e.g., 14d, 36h)")
+	pruneKeepLast := pruneCmd.Int("keep-last", 0, "Always keep the newest N files in each group")
+	pruneMaxSize := pruneCmd.String("max-size", "", "Trim each group to this total size, oldest first (e.g., 20GB)")
+	pruneGroupBy := pruneCmd.String("group-by", "directory", "Apply the rules per 'directory' or per 'pattern'")
+	pruneDryRun := pruneCmd.Bool("dry-run", false, "Show the plan without removing anything")
+	pruneAudit := pruneCmd.String("audit", "", "Audit log to append removals to (default: per-user cache directory)")
+	pruneDisk := pruneCmd.String("disk", "", "Directory to prune")
+
+	// Flags for collect
+	collectPattern := collectCmd.String("pattern", "", "File pattern to collect")
+	collectOut := collectCmd.String("out", "evidence.tar.gz", "Archive to create (.zip or .tar.gz)")
+	collectSignKey := collectCmd.String("sign-key", "", "PEM ed25519 private key used to sign the manifest")
+	collectAll := collectCmd.Bool("all", false, "Search all drives")
+	collectDisk := collectCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for verify
+	verifyArchive := verifyCmd.String("archive", "", "Evidence archive to verify")
+	verifyPubKey := verifyCmd.String("pubkey", "", "PEM ed25519 public key used to check the manifest signature")
+
+	// Flags for empty-dirs
+	emptyDirsRemove := emptyDirsCmd.Bool("remove", false, "Remove the empty directories")
+	emptyDirsAll := emptyDirsCmd.Bool("all", false, "Search all drives")
+	emptyDirsDisk := emptyDirsCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for broken-links
+	brokenLinksRemove := brokenLinksCmd.Bool("remove", false, "Remove dangling and looping links")
+	brokenLinksAll := brokenLinksCmd.Bool("all", false, "Search all drives")
+	brokenLinksDisk := brokenLinksCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for perms-audit
+	permsAuditAllowlist := permsAuditCmd.String("allowlist", "", "File listing expected SUID/SGID binaries")
+	permsAuditUpdate := permsAuditCmd.Bool("update-allowlist", false, "Write the SUID/SGID binaries found to the allowlist file")
+	permsAuditJSON := permsAuditCmd.Bool("json", false, "Print the findings as JSON")
+	permsAuditAll := permsAuditCmd.Bool("all", false, "Search all drives")
+	permsAuditDisk := permsAuditCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for bodyfile
+	bodyfileOut := bodyfileCmd.String("out", "bodyfile.txt", "Bodyfile to write")
+	bodyfileMD5 := bodyfileCmd.Bool("md5", false, "Hash regular files with MD5")
+	bodyfileInclude := bodyfileCmd.String("include", "", "Comma-separated patterns of files to include")
+	bodyfileExclude := bodyfileCmd.String("exclude", "", "Comma-separated patterns of files and directories to skip")
+	bodyfileDisk := bodyfileCmd.String("disk", "", "Directory to walk")
+
+	// Flags for timeline
+	timelineStart := timelineCmd.String("start", "", "Only events at or after this time")
+	timelineEnd := timelineCmd.String("end", "", "Only events before this time")
+	timelinePattern := timelineCmd.String("pattern", "", "Only files whose name or path matches this pattern")
+	timelineType := timelineCmd.String("type", "", "Only events with one of these MACB letters")
+	timelineFormat := timelineCmd.String("format", "csv", "Output format: csv or json")
+	timelineOut := timelineCmd.String("out", "", "File to write the timeline to (default: standard output)")
+
+	// Flags for entropy
+	entropyPattern := entropyCmd.String("pattern", "*", "File pattern to analyze")
+	entropyMin := entropyCmd.Float64("min", 7.5, "Minimum Shannon entropy in bits per byte")
+	entropySample := entropyCmd.Int("sample", 0, "Number of evenly spaced blocks to read (0 reads the whole file)")
+	entropyMinSize := entropyCmd.String("min-size", "1KB", "Skip files smaller than this")
+	entropyIncludeCompressed := entropyCmd.Bool("include-compressed", false, "Also report formats compressed by nature")
+	entropyAll := entropyCmd.Bool("all", false, "Search all drives")
+	entropyDisk := entropyCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for secrets
+	secretsPattern := secretsCmd.String("pattern", "", "Comma-separated patterns of files to scan")
+	secretsRules := secretsCmd.String("rules", "", "JSON file with custom rules and allowlists")
+	secretsMaxSize := secretsCmd.String("max-size", "10MB", "Skip files larger than this")
+	secretsShow := secretsCmd.Bool("show-secrets", false, "Print secrets in full instead of redacted")
+	secretsListRules := secretsCmd.Bool("list-rules", false, "List the enabled rules and exit")
+	secretsJSON := secretsCmd.Bool("json", false, "Print the findings as JSON")
+	secretsAll := secretsCmd.Bool("all", false, "Search all drives")
+	secretsDisk := secretsCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for replace
+	replacePattern := replaceCmd.String("pattern", "", "File pattern to edit")
+	replaceString := replaceCmd.String("string", "", "Literal string or regular expression to replace")
+	replaceReplacement := replaceCmd.String("replacement", "", "Replacement text ($1 and ${name} expand to capture groups with -regex)")
+	replaceRegex := replaceCmd.Bool("regex", false, "Treat -string as a regular expression")
+	replaceBackup := replaceCmd.Bool("backup", false, "Keep the original contents as <file>.bak")
+	replaceDryRun := replaceCmd.Bool("dry-run", false, "Show the diff without writing anything")
+	replaceYes := replaceCmd.Bool("yes", false, "Apply the changes without asking for confirmation")
+	replaceMaxSize := replaceCmd.String("max-size", "10MB", "Skip files larger than this")
+	replaceAll := replaceCmd.Bool("all", false, "Search all drives")
+	replaceDisk := replaceCmd.String("disk", "", "Specific disk to search")
+
+	// Flags for config
+	configProfile := configCmd.String("profile", "", "Profile to show the effective settings for")
+	configCommand := configCmd.String("command", "", "Command to show the effective flag values for")
+
+	// Commands whose flags can be set from the config file
+	commands := map[string]*flag.FlagSet{
+		"count":        countCmd,
+		"remove":       removeCmd,
+		"find":         findCmd,
+		"content":      contentCmd,
+		"extension":    extensionCmd,
+		"sync":         syncCmd,
+		"filetype":     filetypeCmd,
+		"index":        indexCmd,
+		"snapshot":     snapshotCmd,
+		"prune":        pruneCmd,
+		"collect":      collectCmd,
+		"verify":       verifyCmd,
+		"empty-dirs":   emptyDirsCmd,
+		"broken-links": brokenLinksCmd,
+		"perms-audit":  permsAuditCmd,
+		"bodyfile":     bodyfileCmd,
+		"timeline":     timelineCmd,
+		"entropy":      entropyCmd,
+		"secrets":      secretsCmd,
+		"replace":      replaceCmd,
+	}
+
+	// IO limits shared by every command; parseCommand applies them
+	for _, flagSet := range commands {
+		flagSet.Float64("max-read-mbps", 0, "Cap file reads at this many MB/s (0 = unlimited)")
+		flagSet.Float64("max-files-per-sec", 0, "Cap the number of files visited per second (0 = unlimited)")
+		flagSet.Bool("low-impact", false, "Lower the process CPU and IO priority and apply default IO caps")
+	}
+
+	// Load defaults and profiles from the config files
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
 	// Parse subcommands
 	if len(os.Args) < 2 {
 		fmt.Println("File Manager CLI Application")
@@ -108,6 +476,22 @@ func main() {
 		fmt.Println("  find       Find files matching a pattern")
 		fmt.Println("  content    Find files containing specific content")
 		fmt.Println("  extension  Change file extensions")
+		fmt.Println("  filetype   Detect file types from content and flag extension mismatches")
+		fmt.Println("  sync       Mirror a source directory into a destination directory")
+		fmt.Println("  index      Build, update or query the persistent file index")
+		fmt.Println("  snapshot   Create a baseline snapshot of a tree or diff against one")
+		fmt.Println("  prune      Remove files according to a retention policy")
+		fmt.Println("  collect    Package matching files into a hashed evidence archive")
+		fmt.Println("  verify     Verify an evidence archive against its manifest")
+		fmt.Println("  empty-dirs   Find empty directories, bottom-up")
+		fmt.Println("  broken-links Find dangling and looping symbolic links")
+		fmt.Println("  perms-audit Flag risky permissions, SUID/SGID binaries and orphaned owners (Linux and macOS)")
+		fmt.Println("  bodyfile   Write a Sleuth Kit bodyfile for a directory tree")
+		fmt.Println("  timeline   Build a sorted MACB timeline from bodyfiles")
+		fmt.Println("  entropy    List files whose contents look encrypted or packed")
+		fmt.Println("  secrets    Scan files for leaked keys, tokens and passwords")
+		fmt.Println("  replace    Search and replace text across files, with a diff preview")
+		fmt.Println("  config     Show the effective configuration")
 		fmt.Println("Use 'file-manager <command> -h' for more information about a command.")
 		os.Exit(1)
 	}
@@ -121,12 +505,20 @@ func main() {
 			fmt.Println("Example:")
 			fmt.Println("  file-manager count -pattern=\"*.txt\" -all")
 		}
-		countCmd.Parse(os.Args[2:])
+		parseCommand(countCmd, cfg, os.Args[2:])
 		if *countPattern == "" {
 			fmt.Println("Error: File pattern cannot be empty.")
 			os.Exit(1)
 		}
-		count, err := file_manipulation.GetFilesCount(*countPattern, *countAll, *countDisk)
+		var count int
+		var err error
+		if *countUseIndex {
+			var files []string
+			files, err = file_manipulation.FindFilesInIndex(*countIndex, *countPattern, *countAll, *countDisk)
+			count = len(files)
+		} else {
+			count, err = file_manipulation.GetFilesCount(*countPattern, *countAll, *countDisk)
+		}
 		if err != nil {
 			fmt.Println("Error:", err)
 			os.Exit(1)
@@ -141,11 +533,29 @@ func main() {
 			fmt.Println("Example:")
 			fmt.Println("  file-manager remove -pattern=\"*.log\" -disk=\"C:\\\"")
 		}
-		removeCmd.Parse(os.Args[2:])
+		parseCommand(removeCmd, cfg, os.Args[2:])
 		if *removePattern == "" {
 			fmt.Println("Error: File pattern cannot be empty.")
 			os.Exit(1)
 		}
+		if *removeShred {
+			report, err := file_manipulation.RemoveFilesShredded(*removePattern, *removeAll, *removeDisk, file_manipulation.ShredOptions{Passes: *removePasses, Fill: *removeFill})
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			for _, warning := range report.NotGuaranteed {
+				fmt.Printf("Not guaranteed: %s: %s\n", warning.Path, warning.Reason)
+			}
+			for _, failure := range report.Failed {
+				fmt.Println("Failed:", failure)
+			}
+			fmt.Printf("Shredded %d files (%d not guaranteed, %d failed).\n", len(report.Shredded), len(report.NotGuaranteed), len(report.Failed))
+			if len(report.Failed) > 0 {
+				os.Exit(1)
+			}
+			break
+		}
 		if err := file_manipulation.RemoveFiles(*removePattern, *removeAll, *removeDisk); err != nil {
 			fmt.Println("Error:", err)
 			os.Exit(1)
@@ -160,16 +570,25 @@ func main() {
 			fmt.Println("Example:")
 			fmt.Println("  file-manager find -pattern=\"*.go\" -disk=\"/\"")
 		}
-		findCmd.Parse(os.Args[2:])
+		parseCommand(findCmd, cfg, os.Args[2:])
 		if *findPattern == "" {
 			fmt.Println("Error: File pattern cannot be empty.")
 			os.Exit(1)
 		}
-		files, err := file_manipulation.FindFiles(*findPattern, *findAll, *findDisk)
+		var files []string
+		var err error
+		if *findUseIndex {
+			files, err = file_manipulation.FindFilesInIndex(*findIndex, *findPattern, *findAll, *findDisk)
+		} else {
+			files, err = file_manipulation.FindFiles(*findPattern, *findAll, *findDisk)
+		}
 		if err != nil {
 			fmt.Println("Error:", err)
 			os.Exit(1)
 		}
+		if *findDetectedType != "" {
+			files = file_manipulation.FilterFilesByDetectedType(files, *findDetectedType)
+		}
 		fmt.Println("Found files:", files)
 
 	case "content":
@@ -179,12 +598,26 @@ func main() {
 			contentCmd.PrintDefaults()
 			fmt.Println("Example:")
 			fmt.Println("  file-manager content -string=\"TODO\" -type=\".go\" -maxsize=512 -all")
+			fmt.Println("  file-manager content -string=\"Installation failed\" -type=\".log\" -offsets -disk=\"C:\\Windows\\Logs\"")
+			fmt.Println("  file-manager content -string=\"evil.example.com\" -binary -disk=\"/tmp/dumps\"")
 		}
-		contentCmd.Parse(os.Args[2:])
+		parseCommand(contentCmd, cfg, os.Args[2:])
 		if *contentString == "" {
 			fmt.Println("Error: Search string cannot be empty.")
 			os.Exit(1)
 		}
+		if *contentBinary {
+			matches, err := file_manipulation.FindEncodedStringInFiles(*contentString, splitList(*contentEncodings), "*"+*contentType, *contentAll, *contentDisk)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			for _, match := range matches {
+				fmt.Printf("%s: byte %d (%s)\n", match.Path, match.ByteOffset, match.Encoding)
+			}
+			fmt.Printf("Found %d matches\n", len(matches))
+			break
+		}
 		if *contentType == "" {
 			fmt.Println("Error: File type cannot be empty.")
 			os.Exit(1)
@@ -193,6 +626,18 @@ func main() {
 			fmt.Println("Error: Max file size must be greater than 0.")
 			os.Exit(1)
 		}
+		if *contentOffsets {
+			matches, err := file_manipulation.FindContentMatches(*contentString, *contentType, *contentMaxSize, *contentAll, *contentDisk)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			for _, match := range matches {
+				fmt.Printf("%s:%d: %s, byte %d, char %d\n", match.Path, match.Line, match.Encoding, match.ByteOffset, match.CharOffset)
+			}
+			fmt.Printf("Found %d matches\n", len(matches))
+			break
+		}
 		files, err := file_manipulation.FindFilesByContent(*contentString, *contentType, *contentMaxSize, *contentAll, *contentDisk)
 		if err != nil {
 			fmt.Println("Error:", err)
@@ -208,7 +653,7 @@ func main() {
 			fmt.Println("Example:")
 			fmt.Println("  file-manager extension -pattern=\"*.txt\" -new=\".md\" -disk=\"/\"")
 		}
-		extensionCmd.Parse(os.Args[2:])
+		parseCommand(extensionCmd, cfg, os.Args[2:])
 		if *extensionPattern == "" {
 			fmt.Println("Error: File pattern cannot be empty.")
 			os.Exit(1)
@@ -223,8 +668,844 @@ func main() {
 		}
 		fmt.Println("File extensions updated successfully.")
 
+	case "sync":
+		syncCmd.Usage = func() {
+			fmt.Println("Usage: file-manager sync [flags] <source> <destination>")
+			fmt.Println("Flags:")
+			syncCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager sync -delete -dry-run -exclude=\"*.tmp\" /srv/staging /mnt/mirror")
+		}
+		args := parseCommand(syncCmd, cfg, os.Args[2:])
+		if len(args) != 2 {
+			fmt.Println("Error: Sync requires a source and a destination directory.")
+			os.Exit(1)
+		}
+		report, err := file_manipulation.SyncDirectories(args[0], args[1], file_manipulation.SyncOptions{
+			CompareByHash:    *syncHash,
+			DeleteExtraneous: *syncDelete,
+			DryRun:           *syncDryRun,
+			Include:          splitList(*syncInclude),
+			Exclude:          splitList(*syncExclude),
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		for _, path := range report.Added {
+			fmt.Println("add:   ", path)
+		}
+		for _, path := range report.Updated {
+			fmt.Println("update:", path)
+		}
+		for _, path := range report.Deleted {
+			fmt.Println("delete:", path)
+		}
+		for _, failure := range report.Failed {
+			fmt.Println("failed:", failure)
+		}
+		if *syncDryRun {
+			fmt.Printf("Dry run: %d to add, %d to update, %d to delete, %d unchanged\n", len(report.Added), len(report.Updated), len(report.Deleted), report.Unchanged)
+		} else {
+			fmt.Printf("Sync complete: %d added, %d updated, %d deleted, %d unchanged\n", len(report.Added), len(report.Updated), len(report.Deleted), report.Unchanged)
+		}
+		if len(report.Failed) > 0 {
+			os.Exit(1)
+		}
+
+	case "filetype":
+		filetypeCmd.Usage = func() {
+			fmt.Println("Usage: file-manager filetype [flags]")
+			fmt.Println("Flags:")
+			filetypeCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager filetype -pattern=\"*\" -mismatch-only -disk=\"/home/user/Downloads\"")
+		}
+		parseCommand(filetypeCmd, cfg, os.Args[2:])
+		if *filetypePattern == "" {
+			fmt.Println("Error: File pattern cannot be empty.")
+			os.Exit(1)
+		}
+		results, err := file_manipulation.GetFileTypes(*filetypePattern, *filetypeAll, *filetypeDisk)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		mismatches := 0
+		for _, result := range results {
+			if result.Mismatch {
+				mismatches++
+				fmt.Printf("MISMATCH %s: detected %s, extension '%s'\n", result.Path, result.DetectedType, result.Extension)
+			} else if !*filetypeMismatchOnly {
+				fmt.Printf("%s: %s\n", result.Path, result.DetectedType)
+			}
+		}
+		fmt.Printf("Inspected %d files, %d with mismatched extensions\n", len(results), mismatches)
+
+	case "index":
+		indexCmd.Usage = func() {
+			fmt.Println("Usage: file-manager index build|update|query [flags]")
+			fmt.Println("Flags:")
+			indexCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager index build -disk=\"/home\"")
+			fmt.Println("  file-manager index update")
+			fmt.Println("  file-manager index query -pattern=\"*.log\" -min-size=1048576 -newer=24h")
+		}
+		args := parseCommand(indexCmd, cfg, os.Args[2:])
+		if len(args) != 1 {
+			indexCmd.Usage()
+			os.Exit(1)
+		}
+		switch args[0] {
+		case "build", "update":
+			var stats file_manipulation.IndexUpdateStats
+			var err error
+			if args[0] == "build" {
+				stats, err = file_manipulation.BuildFileIndex(*indexFile, *indexAll, *indexDisk, *indexHash)
+			} else {
+				stats, err = file_manipulation.UpdateFileIndex(*indexFile)
+			}
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Index contains %d entries (%d directories scanned, %d reused)\n", stats.Entries, stats.DirectoriesScanned, stats.DirectoriesReused)
+		case "query":
+			index, err := file_manipulation.LoadFileIndex(*indexFile)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			query := file_manipulation.IndexQuery{Pattern: *indexPattern, MinSize: *indexMinSize, MaxSize: *indexMaxSize}
+			if *indexDisk != "" {
+				query.Under = []string{*indexDisk}
+			}
+			if *indexNewer > 0 {
+				query.ModifiedAfter = time.Now().Add(-*indexNewer)
+			}
+			entries := index.Query(query)
+			for _, entry := range entries {
+				fmt.Printf("%s\t%d\t%s\t%s\t%s\n", entry.Mode, entry.Size, entry.ModTime.Format(time.RFC3339), entry.Path, entry.Hash)
+			}
+			fmt.Printf("Found %d entries (index updated %s)\n", len(entries), index.UpdatedAt.Format(time.RFC3339))
+		default:
+			fmt.Printf("Error: Unknown index action '%s'.\n", args[0])
+			os.Exit(1)
+		}
+
+	case "snapshot":
+		snapshotCmd.Usage = func() {
+			fmt.Println("Usage: file-manager snapshot create|diff [flags]")
+			fmt.Println("Flags:")
+			snapshotCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager snapshot create -disk=\"/etc\" -out=\"etc-baseline.json\"")
+			fmt.Println("  file-manager snapshot diff -baseline=\"etc-baseline.json\"")
+			fmt.Println("  file-manager snapshot diff -baseline=\"host1.json\" -against=\"host2.json\"")
+		}
+		args := parseCommand(snapshotCmd, cfg, os.Args[2:])
+		if len(args) != 1 {
+			snapshotCmd.Usage()
+			os.Exit(1)
+		}
+		switch args[0] {
+		case "create":
+			if *snapshotDisk == "" {
+				fmt.Println("Error: Directory to snapshot cannot be empty.")
+				os.Exit(1)
+			}
+			snapshot, err := file_manipulation.CreateSnapshot(*snapshotDisk, *snapshotOut)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Snapshot of %d entries written to %s\n", len(snapshot.Entries), *snapshotOut)
+		case "diff":
+			if *snapshotBaseline == "" {
+				fmt.Println("Error: Baseline snapshot cannot be empty.")
+				os.Exit(1)
+			}
+			baseline, err := file_manipulation.LoadSnapshot(*snapshotBaseline)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			var current file_manipulation.Snapshot
+			if *snapshotAgainst != "" {
+				current, err = file_manipulation.LoadSnapshot(*snapshotAgainst)
+			} else {
+				root := *snapshotDisk
+				if root == "" {
+					root = baseline.Root
+				}
+				current, err = file_manipulation.CaptureSnapshot(root)
+			}
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			changes := file_manipulation.DiffSnapshots(baseline, current)
+			if *snapshotJSON {
+				output, err := json.MarshalIndent(changes, "", "  ")
+				if err != nil {
+					fmt.Println("Error:", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(output))
+			} else {
+				for _, change := range changes {
+					fmt.Printf("%-11s %s\n", change.Change, change.Path)
+					for _, detail := range change.Details {
+						fmt.Printf("            %s\n", detail)
+					}
+				}
+				fmt.Printf("%d changes between %s (%s) and %s (%s)\n", len(changes), baseline.Root, baseline.Hostname, current.Root, current.Hostname)
+			}
+		default:
+			fmt.Printf("Error: Unknown snapshot action '%s'.\n", args[0])
+			os.Exit(1)
+		}
+
+	case "prune":
+		pruneCmd.Usage = func() {
+			fmt.Println("Usage: file-manager prune [flags]")
+			fmt.Println("Flags:")
+			pruneCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager prune -pattern=\"*.log\" -max-age=14d -keep-last=5 -disk=\"/var/log/app\" -dry-run")
+			fmt.Println("  file-manager prune -pattern=\"*\" -max-size=20GB -disk=\"/srv/dumps\"")
+		}
+		parseCommand(pruneCmd, cfg, os.Args[2:])
+		if *prunePattern == "" || *pruneDisk == "" {
+			fmt.Println("Error: File pattern and directory cannot be empty.")
+			os.Exit(1)
+		}
+		maxAge, err := parseAge(*pruneMaxAge)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		maxSize, err := parseSize(*pruneMaxSize)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		plan, err := file_manipulation.PlanPrune(*pruneDisk, file_manipulation.RetentionPolicy{
+			Patterns:     splitList(*prunePattern),
+			MaxAge:       maxAge,
+			KeepLast:     *pruneKeepLast,
+			MaxTotalSize: maxSize,
+			GroupBy:      *pruneGroupBy,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		for _, file := range plan.Remove {
+			fmt.Printf("remove: %s (%d bytes, %s): %s\n", file.Path, file.Size, file.ModTime.Format(time.RFC3339), file.Reason)
+		}
+		if *pruneDryRun {
+			fmt.Printf("Dry run: %d files to remove (%d bytes), %d kept\n", len(plan.Remove), plan.FreedBytes, plan.Kept)
+			break
+		}
+		removed, err := file_manipulation.ApplyPrunePlan(plan, *pruneAudit)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %d of %d planned files, %d kept\n", len(removed), len(plan.Remove), plan.Kept)
+
+	case "collect":
+		collectCmd.Usage = func() {
+			fmt.Println("Usage: file-manager collect [flags]")
+			fmt.Println("Flags:")
+			collectCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager collect -pattern=\"*.sh\" -disk=\"/tmp\" -out=\"case-42.tar.gz\" -sign-key=\"collector.pem\"")
+		}
+		parseCommand(collectCmd, cfg, os.Args[2:])
+		if *collectPattern == "" {
+			fmt.Println("Error: File pattern cannot be empty.")
+			os.Exit(1)
+		}
+		files, err := file_manipulation.FindFiles(*collectPattern, *collectAll, *collectDisk)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		var regularFiles []string
+		for _, file := range files {
+			if info, err := os.Lstat(file); err == nil && info.Mode().IsRegular() {
+				regularFiles = append(regularFiles, file)
+			}
+		}
+		manifest, err := file_manipulation.CollectEvidence(regularFiles, *collectOut, *collectSignKey)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		for _, failure := range manifest.Errors {
+			fmt.Println("Failed:", failure)
+		}
+		fmt.Printf("Collected %d files into %s\n", len(manifest.Files), *collectOut)
+
+	case "verify":
+		verifyCmd.Usage = func() {
+			fmt.Println("Usage: file-manager verify [flags]")
+			fmt.Println("Flags:")
+			verifyCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager verify -archive=\"case-42.tar.gz\" -pubkey=\"collector.pub.pem\"")
+		}
+		parseCommand(verifyCmd, cfg, os.Args[2:])
+		if *verifyArchive == "" {
+			fmt.Println("Error: Archive cannot be empty.")
+			os.Exit(1)
+		}
+		result, err := file_manipulation.VerifyEvidenceArchive(*verifyArchive, *verifyPubKey)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Collected %s on %s by %s\n", result.Manifest.CollectedAt.Format(time.RFC3339), result.Manifest.Hostname, result.Manifest.Collector)
+		for _, name := range result.Mismatched {
+			fmt.Println("Hash mismatch:", name)
+		}
+		for _, name := range result.Missing {
+			fmt.Println("Missing:", name)
+		}
+		for _, name := range result.Unexpected {
+			fmt.Println("Not in manifest:", name)
+		}
+		fmt.Printf("%d files verified, signature: %s\n", len(result.Verified), result.Signature)
+		if !result.OK() {
+			fmt.Println("Verification FAILED.")
+			os.Exit(1)
+		}
+		fmt.Println("Verification passed.")
+
+	case "empty-dirs":
+		emptyDirsCmd.Usage = func() {
+			fmt.Println("Usage: file-manager empty-dirs [flags]")
+			fmt.Println("Flags:")
+			emptyDirsCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager empty-dirs -disk=\"/srv/exports\" -remove")
+		}
+		parseCommand(emptyDirsCmd, cfg, os.Args[2:])
+		dirs, err := file_manipulation.FindEmptyDirectories(*emptyDirsAll, *emptyDirsDisk, *emptyDirsRemove)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		for _, dir := range dirs {
+			fmt.Println(dir)
+		}
+		if *emptyDirsRemove {
+			fmt.Printf("Removed %d empty directories\n", len(dirs))
+		} else {
+			fmt.Printf("Found %d empty directories\n", len(dirs))
+		}
+
+	case "broken-links":
+		brokenLinksCmd.Usage = func() {
+			fmt.Println("Usage: file-manager broken-links [flags]")
+			fmt.Println("Flags:")
+			brokenLinksCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager broken-links -disk=\"/opt\"")
+		}
+		parseCommand(brokenLinksCmd, cfg, os.Args[2:])
+		links, err := file_manipulation.FindBrokenSymlinks(*brokenLinksAll, *brokenLinksDisk, *brokenLinksRemove)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		for _, link := range links {
+			fmt.Printf("%-13s %s -> %s\n", link.Reason, link.Path, link.Target)
+		}
+		fmt.Printf("Found %d broken links\n", len(links))
+
+	case "perms-audit":
+		permsAuditCmd.Usage = func() {
+			fmt.Println("Usage: file-manager perms-audit [flags]")
+			fmt.Println("Flags:")
+			permsAuditCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager perms-audit -disk=\"/\" -allowlist=\"suid-allowlist.txt\"")
+		}
+		parseCommand(permsAuditCmd, cfg, os.Args[2:])
+		if *permsAuditUpdate {
+			if *permsAuditAllowlist == "" {
+				fmt.Println("Error: -update-allowlist needs an -allowlist file.")
+				os.Exit(1)
+			}
+			binaries, err := file_manipulation.WriteSUIDAllowlist(*permsAuditAll, *permsAuditDisk, *permsAuditAllowlist)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Wrote %d SUID/SGID binaries to %s\n", len(binaries), *permsAuditAllowlist)
+			break
+		}
+		findings, err := file_manipulation.AuditPermissions(*permsAuditAll, *permsAuditDisk, file_manipulation.PermissionAuditOptions{
+			SUIDAllowlistPath: *permsAuditAllowlist,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if *permsAuditJSON {
+			output, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			break
+		}
+		for _, finding := range findings {
+			fmt.Printf("%-8s %-24s %s: %s\n", finding.Severity, finding.Check, finding.Path, finding.Detail)
+		}
+		fmt.Printf("Found %d findings\n", len(findings))
+
+	case "bodyfile":
+		bodyfileCmd.Usage = func() {
+			fmt.Println("Usage: file-manager bodyfile [flags]")
+			fmt.Println("Flags:")
+			bodyfileCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager bodyfile -disk=\"/home\" -md5 -out=\"host1.body\"")
+		}
+		parseCommand(bodyfileCmd, cfg, os.Args[2:])
+		if *bodyfileDisk == "" {
+			fmt.Println("Error: Directory to walk cannot be empty.")
+			os.Exit(1)
+		}
+		output, err := os.Create(*bodyfileOut)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		count, err := file_manipulation.WriteBodyfile(*bodyfileDisk, output, file_manipulation.BodyfileOptions{
+			WithMD5: *bodyfileMD5,
+			Include: splitList(*bodyfileInclude),
+			Exclude: splitList(*bodyfileExclude),
+		})
+		if closeErr := output.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d bodyfile lines to %s\n", count, *bodyfileOut)
+
+	case "timeline":
+		timelineCmd.Usage = func() {
+			fmt.Println("Usage: file-manager timeline [flags] <bodyfile>...")
+			fmt.Println("Flags:")
+			timelineCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager timeline -start=\"2024-03-01\" -type=mb -format=json host1.body")
+		}
+		bodyfiles := parseCommand(timelineCmd, cfg, os.Args[2:])
+		if len(bodyfiles) == 0 {
+			timelineCmd.Usage()
+			os.Exit(1)
+		}
+		if *timelineFormat != "csv" && *timelineFormat != "json" {
+			fmt.Printf("Error: Invalid format '%s'.\n", *timelineFormat)
+			os.Exit(1)
+		}
+		start, err := parseTime(*timelineStart)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		end, err := parseTime(*timelineEnd)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		var entries []file_manipulation.BodyfileEntry
+		for _, bodyfile := range bodyfiles {
+			input, err := os.Open(bodyfile)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			parsed, err := file_manipulation.ParseBodyfile(input)
+			input.Close()
+			if err != nil {
+				fmt.Printf("Error: %s: %v\n", bodyfile, err)
+				os.Exit(1)
+			}
+			entries = append(entries, parsed...)
+		}
+		events := file_manipulation.BuildTimeline(entries, file_manipulation.TimelineFilter{
+			Start:   start,
+			End:     end,
+			Pattern: *timelinePattern,
+			Types:   *timelineType,
+		})
+		output := os.Stdout
+		if *timelineOut != "" {
+			output, err = os.Create(*timelineOut)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			defer output.Close()
+		}
+		if *timelineFormat == "json" {
+			err = file_manipulation.WriteTimelineJSON(output, events)
+		} else {
+			err = file_manipulation.WriteTimelineCSV(output, events)
+		}
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if *timelineOut != "" {
+			fmt.Printf("Wrote %d timeline events to %s\n", len(events), *timelineOut)
+		}
+
+	case "entropy":
+		entropyCmd.Usage = func() {
+			fmt.Println("Usage: file-manager entropy [flags]")
+			fmt.Println("Flags:")
+			entropyCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager entropy -min=7.5 -sample=8 -disk=\"/srv/share\"")
+		}
+		parseCommand(entropyCmd, cfg, os.Args[2:])
+		if *entropyPattern == "" {
+			fmt.Println("Error: File pattern cannot be empty.")
+			os.Exit(1)
+		}
+		minSize, err := parseSize(*entropyMinSize)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		results, err := file_manipulation.FindHighEntropyFiles(*entropyPattern, *entropyMin, *entropyIncludeCompressed, *entropyAll, *entropyDisk, file_manipulation.EntropyOptions{
+			SampleBlocks: *entropySample,
+			MinSize:      minSize,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		for _, result := range results {
+			fmt.Printf("%.3f %10d %-10s %s\n", result.Entropy, result.Size, result.DetectedType, result.Path)
+		}
+		fmt.Printf("Found %d files with entropy of at least %.2f\n", len(results), *entropyMin)
+
+	case "secrets":
+		secretsCmd.Usage = func() {
+			fmt.Println("Usage: file-manager secrets [flags]")
+			fmt.Println("Flags:")
+			secretsCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager secrets -disk=\"/srv/app\" -rules=\"secrets.json\"")
+		}
+		parseCommand(secretsCmd, cfg, os.Args[2:])
+		var secretsConfig file_manipulation.SecretsConfig
+		if *secretsRules != "" {
+			secretsConfig, err = file_manipulation.LoadSecretsConfig(*secretsRules)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+		if *secretsListRules {
+			rules := secretsConfig.Rules
+			if !secretsConfig.DisableDefaultRules {
+				rules = append(file_manipulation.DefaultSecretRules(), rules...)
+			}
+			for _, rule := range rules {
+				fmt.Printf("%-28s %s\n", rule.ID, rule.Description)
+			}
+			break
+		}
+		maxSize, err := parseSize(*secretsMaxSize)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		findings, err := file_manipulation.FindSecrets(*secretsAll, *secretsDisk, file_manipulation.SecretScanOptions{
+			Config:      secretsConfig,
+			Include:     splitList(*secretsPattern),
+			MaxFileSize: maxSize,
+			ShowSecrets: *secretsShow,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if *secretsJSON {
+			output, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			break
+		}
+		for _, finding := range findings {
+			fmt.Printf("%s:%d %s: %s\n", finding.Path, finding.Line, finding.RuleID, finding.Secret)
+		}
+		fmt.Printf("Found %d possible secrets\n", len(findings))
+
+	case "replace":
+		replaceCmd.Usage = func() {
+			fmt.Println("Usage: file-manager replace [flags]")
+			fmt.Println("Flags:")
+			replaceCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager replace -pattern=\"*.conf\" -string=\"listen (\\d+)\" -replacement=\"listen 8$1\" -regex -backup -disk=\"/etc/app\"")
+		}
+		parseCommand(replaceCmd, cfg, os.Args[2:])
+		if *replacePattern == "" || *replaceString == "" {
+			fmt.Println("Error: File pattern and search string cannot be empty.")
+			os.Exit(1)
+		}
+		maxSize, err := parseSize(*replaceMaxSize)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		options := file_manipulation.ReplaceOptions{Regex: *replaceRegex, Backup: *replaceBackup, MaxFileSize: maxSize}
+		plan, err := file_manipulation.PlanReplacements(*replacePattern, *replaceString, *replaceReplacement, options, *replaceAll, *replaceDisk)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		total := 0
+		for _, change := range plan.Changes {
+			fmt.Print(change.Diff)
+			total += change.Replacements
+		}
+		for _, skipped := range plan.Skipped {
+			fmt.Println("Skipped:", skipped)
+		}
+		if len(plan.Changes) == 0 {
+			fmt.Println("No files to change.")
+			break
+		}
+		if *replaceDryRun {
+			fmt.Printf("Would make %d replacements in %d files\n", total, len(plan.Changes))
+			break
+		}
+		if !*replaceYes {
+			fmt.Printf("Apply %d replacements in %d files? [y/N]: ", total, len(plan.Changes))
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if answer != "y" && answer != "yes" {
+				fmt.Println("No files were changed.")
+				break
+			}
+		}
+		written, failed := file_manipulation.ApplyReplacements(plan, options)
+		for _, path := range written {
+			fmt.Println("Updated:", path)
+		}
+		for _, failure := range failed {
+			fmt.Println("Failed:", failure)
+		}
+		fmt.Printf("Updated %d files, %d failed\n", len(written), len(failed))
+		if len(failed) > 0 {
+			os.Exit(1)
+		}
+
+	case "config":
+		configCmd.Usage = func() {
+			fmt.Println("Usage: file-manager config show [flags]")
+			fmt.Println("Flags:")
+			configCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  file-manager config show -command=content -profile=weekly-log-sweep")
+		}
+		args := parseInterspersed(configCmd, os.Args[2:])
+		if len(args) != 1 || args[0] != "show" {
+			configCmd.Usage()
+			os.Exit(1)
+		}
+		fmt.Println("Config files loaded:", cfg.Sources)
+		if *configCommand == "" {
+			output, err := json.MarshalIndent(cfg, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			break
+		}
+		flagSet, ok := commands[*configCommand]
+		if !ok {
+			fmt.Printf("Error: Unknown command '%s'.\n", *configCommand)
+			os.Exit(1)
+		}
+		var configArgs []string
+		if *configProfile != "" {
+			configArgs = []string{"-profile=" + *configProfile}
+		}
+		parseCommand(flagSet, cfg, configArgs)
+		fmt.Printf("Effective flags for %s:\n", *configCommand)
+		flagSet.VisitAll(func(f *flag.Flag) {
+			fmt.Printf("  -%s=%s\n", f.Name, f.Value.String())
+		})
+
 	default:
 		fmt.Println("Unknown command. Use 'file-manager -h' for help.")
 		os.Exit(1)
 	}
 }
+
+// parseCommand parses a command's flags and fills in values from the config file.
+//
+// Flag values come from, in increasing priority: the flag defaults, the configured defaults
+// (non-destructive flags only, see config.GlobalFlags), the configured command defaults, the
+// selected -profile, and the command line.
+func parseCommand(flagSet *flag.FlagSet, cfg config.Config, args []string) []string {
+	if flagSet.Lookup("profile") == nil {
+		flagSet.String("profile", "", "Named profile from the config file")
+	}
+	positional := parseInterspersed(flagSet, args)
+
+	explicit := map[string]bool{}
+	flagSet.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	configured, err := cfg.EffectiveFlags(flagSet.Name(), flagSet.Lookup("profile").Value.String())
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	for name, value := range configured {
+		if explicit[name] || name == "profile" || flagSet.Lookup(name) == nil {
+			continue
+		}
+		if err := flagSet.Set(name, value); err != nil {
+			fmt.Printf("Error: Invalid config value for -%s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+
+	applyIOLimits(flagSet)
+	return positional
+}
+
+// applyIOLimits applies the -max-read-mbps, -max-files-per-sec and -low-impact flags of a command.
+func applyIOLimits(flagSet *flag.FlagSet) {
+	readRate, filesRate, lowImpact := flagSet.Lookup("max-read-mbps"), flagSet.Lookup("max-files-per-sec"), flagSet.Lookup("low-impact")
+	if readRate == nil || filesRate == nil || lowImpact == nil {
+		return
+	}
+	readMBps := readRate.Value.(flag.Getter).Get().(float64)
+	filesPerSec := filesRate.Value.(flag.Getter).Get().(float64)
+	if readMBps < 0 || filesPerSec < 0 {
+		fmt.Println("Error: IO limits cannot be negative.")
+		os.Exit(1)
+	}
+	file_manipulation.SetIOLimits(file_manipulation.IOLimits{
+		ReadBytesPerSecond: int64(readMBps * 1024 * 1024),
+		FilesPerSecond:     filesPerSec,
+	})
+	if lowImpact.Value.(flag.Getter).Get().(bool) {
+		if err := file_manipulation.EnableLowImpactMode(); err != nil {
+			fmt.Println("Warning:", err)
+		}
+	}
+}
+
+// parseAge parses a duration that may also be given in days ("14d") or weeks ("2w").
+func parseAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid age: %s", value)
+			}
+			return time.Duration(count * float64(unit)), nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age: %s", value)
+	}
+	return age, nil
+}
+
+// parseTime parses an absolute time (RFC 3339, "2006-01-02 15:04:05" or "2006-01-02", in UTC)
+// or an age such as "7d", meaning that long before now.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	age, err := parseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s", value)
+	}
+	return time.Now().Add(-age), nil
+}
+
+// parseSize parses a byte size with an optional KB, MB, GB or TB suffix (powers of 1024).
+func parseSize(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	number := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if trimmed, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, multiplier = trimmed, unit.multiplier
+			break
+		}
+	}
+	size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return int64(size * float64(multiplier)), nil
+}
+
+// parseInterspersed parses flags that may appear before, between or after positional arguments
+// and returns the positional arguments.
+func parseInterspersed(flagSet *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flagSet.Parse(args)
+		args = flagSet.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// splitList splits a comma-separated flag value into its non-empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,613 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Protheophage/GO/pkg/investigation_tools"
+	"github.com/Protheophage/GO/pkg/random_utilities"
+)
+
+func main() {
+	// Global help flag
+	if len(os.Args) > 1 && (os.Args[1] == "-h" || os.Args[1] == "--help") {
+		fmt.Println("Investigation Tools CLI Application")
+		fmt.Println("Usage:")
+		fmt.Println("  investigation-tools <command> [flags]")
+		fmt.Println("Commands:")
+		fmt.Println("  connections  List network connections and the processes that own them")
+		fmt.Println("    Usage: investigation-tools connections [flags] [filter terms...]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -filter: Filter specification, e.g. 'remote=10.0.0.0/8 rport=443 state=established'.")
+		fmt.Println("               Keys: remote (or ip), local, rport, lport, port, state, proto, pid, process.")
+		fmt.Println("               Values of one key are comma-separated; ports may be ranges such as 8000-8999.")
+		fmt.Println("      -json: Print the connections as JSON (default: false).")
+		fmt.Println("  monitor      Report network connections as they open and close")
+		fmt.Println("    Usage: investigation-tools monitor [flags] [filter terms...]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -interval: Time between polls of the socket table (default: 2s).")
+		fmt.Println("      -filter: Filter specification, with the same keys as for connections.")
+		fmt.Println("      -listening: Also report listening, unconnected and Unix domain sockets (default: false).")
+		fmt.Println("      -existing: Report the connections already open at startup as opened (default: false).")
+		fmt.Println("      -ndjson: Stream the events to standard output as NDJSON (default: false).")
+		fmt.Println("      -out: Also append the events as NDJSON to this file.")
+		fmt.Println("      -duration: Stop after this long (default: run until interrupted).")
+		fmt.Println("  beacons      Rank processes by how regularly they connect to the same destination")
+		fmt.Println("    Usage: investigation-tools beacons [flags] [filter terms...]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -in: Analyze a saved NDJSON capture from 'monitor -out' instead of monitoring live ('-' for standard input).")
+		fmt.Println("      -interval: Time between polls of the socket table when monitoring live (default: 1s).")
+		fmt.Println("      -duration: How long to monitor live (default: 10m; Ctrl+C stops early).")
+		fmt.Println("      -filter: Filter specification for live monitoring, with the same keys as for connections.")
+		fmt.Println("      -out: Also append the live events as NDJSON to this file.")
+		fmt.Println("      -min-connections: Connections a process and destination need before they are scored (default: 4).")
+		fmt.Println("      -min-score: Hide results scoring below this, from 0 to 1 (default: 0).")
+		fmt.Println("      -top: Show at most this many results (default: 20, 0 for all).")
+		fmt.Println("      -json: Print the results as JSON (default: false).")
+		fmt.Println("    Live monitoring polls the socket table, so connections shorter than -interval are only seen through their")
+		fmt.Println("    TIME_WAIT socket and attributed to the last process seen with the same port and destination. Connections")
+		fmt.Println("    closed first by the remote end leave no TIME_WAIT socket and are missed; use a shorter -interval for them.")
+		fmt.Println("  processes    List running processes or draw the process tree")
+		fmt.Println("    Usage: investigation-tools processes [flags]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -user: Comma-separated user names or UIDs.")
+		fmt.Println("      -name: Comma-separated process names or glob patterns (e.g., 'python*').")
+		fmt.Println("      -parent: Comma-separated parent PIDs.")
+		fmt.Println("      -descendants: With -parent, include all descendants, not just children (default: false).")
+		fmt.Println("      -tree: Draw the processes as a tree (default: false).")
+		fmt.Println("      -json: Print the processes as JSON (default: false).")
+		fmt.Println("  triage       Flag processes that use common Linux implant tricks (Linux only)")
+		fmt.Println("    Usage: investigation-tools triage [flags]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -recover: Copy the executables of flagged processes to this directory, named by SHA-256.")
+		fmt.Println("      -temp-dirs: Comma-separated directories programs should not run from (default: /tmp,/var/tmp,/dev/shm).")
+		fmt.Println("      -json: Print the findings as JSON (default: false).")
+		fmt.Println("  logons       List user logon sessions and failed logons")
+		fmt.Println("    Usage: investigation-tools logons [flags]")
+		fmt.Println("    Flags:")
+		fmt.Println("      -user: Username to filter, '*' wildcards allowed (default: *).")
+		fmt.Println("      -since: Only show logons within this long before now, e.g. 24h (default: all).")
+		fmt.Println("      -wtmp: Read sessions from this wtmp or utmp file, e.g. a copy from another host.")
+		fmt.Println("      -btmp: Read failed logons from this btmp file (used with -wtmp).")
+		fmt.Println("      -json: Print the sessions as JSON (default: false).")
+		fmt.Println()
+		fmt.Println("Use 'investigation-tools <command> -h' for more information about a command.")
+		os.Exit(0)
+	}
+
+	// Define subcommands
+	connectionsCmd := flag.NewFlagSet("connections", flag.ExitOnError)
+	monitorCmd := flag.NewFlagSet("monitor", flag.ExitOnError)
+	beaconsCmd := flag.NewFlagSet("beacons", flag.ExitOnError)
+	processesCmd := flag.NewFlagSet("processes", flag.ExitOnError)
+	triageCmd := flag.NewFlagSet("triage", flag.ExitOnError)
+	logonsCmd := flag.NewFlagSet("logons", flag.ExitOnError)
+
+	// Flags for connections
+	connectionsFilter := connectionsCmd.String("filter", "", "Filter specification (e.g., 'remote=10.0.0.0/8 rport=443 state=established')")
+	connectionsJSON := connectionsCmd.Bool("json", false, "Print the connections as JSON")
+
+	// Flags for monitor
+	monitorInterval := monitorCmd.Duration("interval", 2*time.Second, "Time between polls of the socket table")
+	monitorFilter := monitorCmd.String("filter", "", "Filter specification (e.g., 'remote=10.0.0.0/8 rport=443')")
+	monitorListening := monitorCmd.Bool("listening", false, "Also report listening, unconnected and Unix domain sockets")
+	monitorExisting := monitorCmd.Bool("existing", false, "Report the connections already open at startup as opened")
+	monitorNDJSON := monitorCmd.Bool("ndjson", false, "Stream the events to standard output as NDJSON")
+	monitorOut := monitorCmd.String("out", "", "Also append the events as NDJSON to this file")
+	monitorDuration := monitorCmd.Duration("duration", 0, "Stop after this long (0 = run until interrupted)")
+
+	// Flags for beacons
+	beaconsIn := beaconsCmd.String("in", "", "Analyze a saved NDJSON capture instead of monitoring live ('-' for standard input)")
+	beaconsInterval := beaconsCmd.Duration("interval", time.Second, "Time between polls of the socket table when monitoring live")
+	beaconsDuration := beaconsCmd.Duration("duration", 10*time.Minute, "How long to monitor live")
+	beaconsFilter := beaconsCmd.String("filter", "", "Filter specification for live monitoring")
+	beaconsOut := beaconsCmd.String("out", "", "Also append the live events as NDJSON to this file")
+	beaconsMinConnections := beaconsCmd.Int("min-connections", 4, "Connections a process and destination need before they are scored")
+	beaconsMinScore := beaconsCmd.Float64("min-score", 0, "Hide results scoring below this (0 to 1)")
+	beaconsTop := beaconsCmd.Int("top", 20, "Show at most this many results (0 = all)")
+	beaconsJSON := beaconsCmd.Bool("json", false, "Print the results as JSON")
+
+	// Flags for processes
+	processesUser := processesCmd.String("user", "", "Comma-separated user names or UIDs")
+	processesName := processesCmd.String("name", "", "Comma-separated process names or glob patterns")
+	processesParent := processesCmd.String("parent", "", "Comma-separated parent PIDs")
+	processesDescendants := processesCmd.Bool("descendants", false, "With -parent, include all descendants, not just children")
+	processesTree := processesCmd.Bool("tree", false, "Draw the processes as a tree")
+	processesJSON := processesCmd.Bool("json", false, "Print the processes as JSON")
+
+	// Flags for triage
+	triageRecover := triageCmd.String("recover", "", "Copy the executables of flagged processes to this directory, named by SHA-256")
+	triageTempDirs := triageCmd.String("temp-dirs", strings.Join(investigation_tools.DefaultTempDirectories, ","), "Comma-separated directories programs should not run from")
+	triageJSON := triageCmd.Bool("json", false, "Print the findings as JSON")
+
+	// Flags for logons
+	logonsUser := logonsCmd.String("user", "*", "Username to filter ('*' wildcards allowed)")
+	logonsSince := logonsCmd.Duration("since", 0, "Only show logons within this long before now (0 = all)")
+	logonsWtmp := logonsCmd.String("wtmp", "", "Read sessions from this wtmp or utmp file")
+	logonsBtmp := logonsCmd.String("btmp", "", "Read failed logons from this btmp file (used with -wtmp)")
+	logonsJSON := logonsCmd.Bool("json", false, "Print the sessions as JSON")
+
+	// Parse subcommands
+	if len(os.Args) < 2 {
+		fmt.Println("Investigation Tools CLI Application")
+		fmt.Println("Usage:")
+		fmt.Println("  investigation-tools <command> [flags]")
+		fmt.Println("Commands:")
+		fmt.Println("  connections  List network connections and the processes that own them")
+		fmt.Println("  monitor      Report network connections as they open and close")
+		fmt.Println("  beacons      Rank processes by how regularly they connect to the same destination")
+		fmt.Println("  processes    List running processes or draw the process tree")
+		fmt.Println("  triage       Flag processes that use common Linux implant tricks (Linux only)")
+		fmt.Println("  logons       List user logon sessions and failed logons")
+		fmt.Println("Use 'investigation-tools <command> -h' for more information about a command.")
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "connections":
+		connectionsCmd.Usage = func() {
+			fmt.Println("Usage: investigation-tools connections [flags] [filter terms...]")
+			fmt.Println("Flags:")
+			connectionsCmd.PrintDefaults()
+			fmt.Println("Filter keys: remote (or ip), local, rport, lport, port, state, proto, pid, process")
+			fmt.Println("Example:")
+			fmt.Println("  investigation-tools connections -filter=\"remote=10.0.0.0/8,2001:db8::/32 rport=443 state=established\"")
+			fmt.Println("  investigation-tools connections proto=tcp state=listen lport=1-1024")
+		}
+		terms := parseInterspersed(connectionsCmd, os.Args[2:])
+		filter, err := investigation_tools.ParseConnectionFilter(strings.Join(append([]string{*connectionsFilter}, terms...), " "))
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		connections, err := investigation_tools.GetNetworkConnections(filter)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if *connectionsJSON {
+			output, err := json.MarshalIndent(connections, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			break
+		}
+		printConnections(connections)
+		fmt.Printf("Found %d connections\n", len(connections))
+
+	case "monitor":
+		monitorCmd.Usage = func() {
+			fmt.Println("Usage: investigation-tools monitor [flags] [filter terms...]")
+			fmt.Println("Flags:")
+			monitorCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  investigation-tools monitor -interval=1s process=python*")
+			fmt.Println("  investigation-tools monitor -ndjson -out=connections.ndjson rport=443")
+		}
+		terms := parseInterspersed(monitorCmd, os.Args[2:])
+		filter, err := investigation_tools.ParseConnectionFilter(strings.Join(append([]string{*monitorFilter}, terms...), " "))
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		var handlers []func(investigation_tools.ConnectionEvent)
+		if *monitorNDJSON {
+			handlers = append(handlers, investigation_tools.WriteConnectionEventsNDJSON(os.Stdout))
+		} else {
+			handlers = append(handlers, printConnectionEvent)
+		}
+		if *monitorOut != "" {
+			file, err := os.OpenFile(*monitorOut, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			handlers = append(handlers, investigation_tools.WriteConnectionEventsNDJSON(file))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if *monitorDuration > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, *monitorDuration)
+			defer cancel()
+		}
+
+		options := investigation_tools.MonitorOptions{
+			Interval:         *monitorInterval,
+			Filter:           filter,
+			IncludeListening: *monitorListening,
+			ReportExisting:   *monitorExisting,
+		}
+		if !*monitorNDJSON {
+			fmt.Printf("Monitoring network connections every %s. Press Ctrl+C to stop.\n", options.Interval)
+		}
+		err = investigation_tools.MonitorNetworkConnections(ctx, options, func(event investigation_tools.ConnectionEvent) {
+			for _, handler := range handlers {
+				handler(event)
+			}
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+	case "beacons":
+		beaconsCmd.Usage = func() {
+			fmt.Println("Usage: investigation-tools beacons [flags] [filter terms...]")
+			fmt.Println("Flags:")
+			beaconsCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  investigation-tools beacons -duration=30m -out=connections.ndjson")
+			fmt.Println("  investigation-tools beacons -in=connections.ndjson -min-score=0.7")
+		}
+		terms := parseInterspersed(beaconsCmd, os.Args[2:])
+		analyzer := investigation_tools.NewBeaconAnalyzer(investigation_tools.BeaconOptions{
+			MinConnections: *beaconsMinConnections,
+			MinScore:       *beaconsMinScore,
+		})
+
+		if *beaconsIn != "" {
+			input := os.Stdin
+			if *beaconsIn != "-" {
+				file, err := os.Open(*beaconsIn)
+				if err != nil {
+					fmt.Println("Error:", err)
+					os.Exit(1)
+				}
+				defer file.Close()
+				input = file
+			}
+			if err := investigation_tools.ReadConnectionEventsNDJSON(input, analyzer.Observe); err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		} else {
+			filter, err := investigation_tools.ParseConnectionFilter(strings.Join(append([]string{*beaconsFilter}, terms...), " "))
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			onEvent := analyzer.Observe
+			if *beaconsOut != "" {
+				file, err := os.OpenFile(*beaconsOut, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+				if err != nil {
+					fmt.Println("Error:", err)
+					os.Exit(1)
+				}
+				defer file.Close()
+				writeEvent := investigation_tools.WriteConnectionEventsNDJSON(file)
+				onEvent = func(event investigation_tools.ConnectionEvent) {
+					writeEvent(event)
+					analyzer.Observe(event)
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, *beaconsDuration)
+			defer cancel()
+
+			fmt.Fprintf(os.Stderr, "Monitoring network connections for %s. Press Ctrl+C to stop early.\n", *beaconsDuration)
+			err = investigation_tools.MonitorNetworkConnections(ctx, investigation_tools.MonitorOptions{Interval: *beaconsInterval, Filter: filter, CountTimeWait: true}, onEvent)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+
+		candidates := analyzer.Results()
+		if *beaconsTop > 0 && len(candidates) > *beaconsTop {
+			candidates = candidates[:*beaconsTop]
+		}
+		if *beaconsJSON {
+			output, err := json.MarshalIndent(candidates, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			break
+		}
+		printBeaconCandidates(candidates)
+		fmt.Printf("Found %d candidates\n", len(candidates))
+
+	case "processes":
+		processesCmd.Usage = func() {
+			fmt.Println("Usage: investigation-tools processes [flags]")
+			fmt.Println("Flags:")
+			processesCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  investigation-tools processes -tree")
+			fmt.Println("  investigation-tools processes -parent=1234 -descendants -tree")
+			fmt.Println("  investigation-tools processes -user=www-data -name='sh,bash,python*' -json")
+		}
+		processesCmd.Parse(os.Args[2:])
+		filter := investigation_tools.ProcessFilter{
+			Users:       splitList(*processesUser),
+			Names:       splitList(*processesName),
+			Descendants: *processesDescendants,
+		}
+		for _, value := range splitList(*processesParent) {
+			pid, err := strconv.Atoi(value)
+			if err != nil {
+				fmt.Println("Error: invalid parent PID:", value)
+				os.Exit(1)
+			}
+			filter.ParentPIDs = append(filter.ParentPIDs, pid)
+		}
+
+		processes, err := investigation_tools.GetProcesses()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		processes = investigation_tools.FilterProcesses(processes, filter)
+		switch {
+		case *processesJSON:
+			output, err := json.MarshalIndent(processes, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+		case *processesTree:
+			fmt.Print(investigation_tools.RenderProcessTree(processes))
+		default:
+			printProcesses(processes)
+			fmt.Printf("Found %d processes\n", len(processes))
+		}
+
+	case "triage":
+		triageCmd.Usage = func() {
+			fmt.Println("Usage: investigation-tools triage [flags]")
+			fmt.Println("Flags:")
+			triageCmd.PrintDefaults()
+			fmt.Println("Reasons: deleted-exe, memfd-exe, temp-dir-exe, hidden-dir-exe, name-mismatch, fake-kernel-thread, hash-failed")
+			fmt.Println("Example:")
+			fmt.Println("  investigation-tools triage -recover=/root/evidence")
+		}
+		triageCmd.Parse(os.Args[2:])
+		suspicious, err := investigation_tools.TriageProcesses(investigation_tools.ProcessTriageOptions{
+			TempDirectories: splitList(*triageTempDirs),
+			RecoverDir:      *triageRecover,
+		})
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if *triageJSON {
+			output, err := json.MarshalIndent(suspicious, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			break
+		}
+		for _, result := range suspicious {
+			fmt.Printf("PID %d %s (user %s, parent %d)\n", result.Process.PID, result.Process.Name, result.Process.User, result.Process.PPID)
+			fmt.Printf("  exe:     %s\n", result.Process.ExePath)
+			fmt.Printf("  cmdline: %s\n", strings.Join(strings.Fields(result.Process.Cmdline), " "))
+			if result.SHA256 != "" {
+				fmt.Printf("  sha256:  %s\n", result.SHA256)
+			}
+			if result.RecoveredPath != "" {
+				fmt.Printf("  saved:   %s\n", result.RecoveredPath)
+			}
+			for _, finding := range result.Findings {
+				fmt.Printf("  [%s] %s\n", finding.Reason, finding.Detail)
+			}
+		}
+		fmt.Printf("Found %d suspicious processes\n", len(suspicious))
+
+	case "logons":
+		logonsCmd.Usage = func() {
+			fmt.Println("Usage: investigation-tools logons [flags]")
+			fmt.Println("Flags:")
+			logonsCmd.PrintDefaults()
+			fmt.Println("Example:")
+			fmt.Println("  investigation-tools logons -since=72h -user=root")
+			fmt.Println("  investigation-tools logons -wtmp=/evidence/host1/wtmp -btmp=/evidence/host1/btmp -json")
+		}
+		logonsCmd.Parse(os.Args[2:])
+		var startDate time.Time
+		if *logonsSince > 0 {
+			startDate = time.Now().Add(-*logonsSince)
+		}
+
+		var sessions []investigation_tools.LogonSession
+		if *logonsWtmp != "" {
+			records, err := investigation_tools.ReadUtmpFile(*logonsWtmp)
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			var failures []investigation_tools.UtmpRecord
+			if *logonsBtmp != "" {
+				failures, err = investigation_tools.ReadUtmpFile(*logonsBtmp)
+				if err != nil {
+					fmt.Println("Error:", err)
+					os.Exit(1)
+				}
+			}
+			for _, session := range investigation_tools.ReconstructLogonSessions(records, failures) {
+				if session.LogonTime.Before(startDate) {
+					continue
+				}
+				if *logonsUser != "*" && !random_utilities.MatchesWildcard(*logonsUser, session.UserName) {
+					continue
+				}
+				sessions = append(sessions, session)
+			}
+		} else {
+			var err error
+			sessions, err = investigation_tools.GetUserLogonSessions(*logonsUser, startDate, time.Time{})
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+		}
+
+		if *logonsJSON {
+			output, err := json.MarshalIndent(sessions, "", "  ")
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(output))
+			break
+		}
+		printLogonSessions(sessions)
+		fmt.Printf("Found %d sessions\n", len(sessions))
+
+	default:
+		fmt.Println("Unknown command. Use 'investigation-tools -h' for help.")
+		os.Exit(1)
+	}
+}
+
+// printConnections prints connections as a table.
+func printConnections(connections []investigation_tools.NetworkConnection) {
+	fmt.Printf("%-6s %-40s %-40s %-12s %8s %s\n", "PROTO", "LOCAL", "REMOTE", "STATE", "PID", "PROCESS")
+	for _, connection := range connections {
+		fmt.Printf("%-6s %-40s %-40s %-12s %8s %s\n",
+			connection.Protocol,
+			formatEndpoint(connection.LocalAddress, connection.LocalPort),
+			formatEndpoint(connection.RemoteAddress, connection.RemotePort),
+			connection.State,
+			connection.ProcessId,
+			connection.ProcessName)
+	}
+}
+
+// printConnectionEvent prints a connection event as one line.
+func printConnectionEvent(event investigation_tools.ConnectionEvent) {
+	connection := event.Connection
+	line := fmt.Sprintf("%s %-6s %-5s %s -> %s pid=%s process=%s",
+		event.Time.Format(time.RFC3339),
+		strings.ToUpper(event.Event),
+		connection.Protocol,
+		formatEndpoint(connection.LocalAddress, connection.LocalPort),
+		formatEndpoint(connection.RemoteAddress, connection.RemotePort),
+		connection.ProcessId,
+		connection.ProcessName)
+	if event.Event == "closed" {
+		duration := event.Duration.Round(time.Second).String()
+		if event.Preexisting {
+			duration = ">" + duration
+		}
+		line += " duration=" + duration
+	}
+	fmt.Println(line)
+}
+
+// printBeaconCandidates prints beacon candidates as a table.
+func printBeaconCandidates(candidates []investigation_tools.BeaconCandidate) {
+	fmt.Printf("%-5s %-20s %-40s %5s %10s %10s %6s %s\n", "SCORE", "PROCESS", "REMOTE", "CONNS", "INTERVAL", "JITTER", "RATIO", "PIDS")
+	for _, candidate := range candidates {
+		fmt.Printf("%-5.2f %-20s %-40s %5d %10s %10s %6.2f %s\n",
+			candidate.Score,
+			candidate.ProcessName,
+			formatEndpoint(candidate.RemoteAddress, candidate.RemotePort),
+			candidate.Connections,
+			candidate.MeanInterval.Round(time.Millisecond),
+			candidate.Jitter.Round(time.Millisecond),
+			candidate.JitterRatio,
+			strings.Join(candidate.ProcessIds, ","))
+	}
+}
+
+// printProcesses prints processes as a table.
+func printProcesses(processes []investigation_tools.Process) {
+	fmt.Printf("%7s %7s %-12s %10s %-19s %s\n", "PID", "PPID", "USER", "RSS", "STARTED", "COMMAND")
+	for _, process := range processes {
+		command := strings.Join(strings.Fields(process.Cmdline), " ")
+		if command == "" {
+			command = "[" + process.Name + "]"
+		}
+		started := ""
+		if !process.StartTime.IsZero() {
+			started = process.StartTime.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%7d %7d %-12s %9dK %-19s %s\n",
+			process.PID,
+			process.PPID,
+			process.User,
+			process.RSS/1024,
+			started,
+			command)
+	}
+}
+
+// printLogonSessions prints logon sessions as a table.
+func printLogonSessions(sessions []investigation_tools.LogonSession) {
+	fmt.Printf("%-16s %-12s %-24s %-19s %-19s %10s %-15s %s\n", "USER", "TERMINAL", "SOURCE", "LOGON", "LOGOFF", "DURATION", "STATUS", "FAILED BEFORE")
+	for _, session := range sessions {
+		source := session.SourceHost
+		if session.SourceIP != "" && session.SourceIP != source {
+			source = strings.TrimSpace(source + " " + session.SourceIP)
+		}
+		logoff, duration := "", ""
+		if !session.LogoffTime.IsZero() {
+			logoff = session.LogoffTime.Format("2006-01-02 15:04:05")
+			duration = session.Duration.Round(time.Second).String()
+		}
+		fmt.Printf("%-16s %-12s %-24s %-19s %-19s %10s %-15s %d\n",
+			session.UserName,
+			session.Terminal,
+			source,
+			session.LogonTime.Format("2006-01-02 15:04:05"),
+			logoff,
+			duration,
+			session.Status,
+			session.FailedAttempts)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// formatEndpoint joins an address and port, bracketing IPv6 addresses; Unix sockets have no port.
+func formatEndpoint(address, port string) string {
+	if port == "" {
+		return address
+	}
+	return net.JoinHostPort(address, port)
+}
+
+// parseInterspersed parses flags that may appear before, between or after positional arguments
+// and returns the positional arguments.
+func parseInterspersed(flagSet *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flagSet.Parse(args)
+		args = flagSet.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
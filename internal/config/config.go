@@ -0,0 +1,259 @@
+// This module is cross-platform (Windows and Linux).
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// Profile is a named set of flag values for one command (a saved search).
+type Profile struct {
+	Command string            `json:"command"`
+	Flags   map[string]string `json:"flags"`
+}
+
+// Config holds flag defaults and named profiles loaded from the config files.
+type Config struct {
+	Defaults map[string]string            `json:"defaults,omitempty"` // Values for the non-destructive flags in GlobalFlags, applied to every command that has the flag
+	Commands map[string]map[string]string `json:"commands,omitempty"` // Flag values applied to a single command
+	Profiles map[string]Profile           `json:"profiles,omitempty"` // Named profiles selected with -profile
+	Sources  []string                     `json:"-"`                  // Config files that were loaded, in order
+}
+
+// GlobalFlags lists the flags the "defaults" section may set. They only limit how much a command reads,
+// so a global default can never make a command delete, overwrite or move files. Destructive flags such as
+// -disk, -all, -yes, -delete or -remove must be set per command or in a profile selected with -profile.
+var GlobalFlags = map[string]bool{
+	"max-read-mbps":     true,
+	"max-files-per-sec": true,
+	"low-impact":        true,
+	"maxsize":           true,
+	"max-size":          true,
+}
+
+// SystemConfigPath returns the path of the system-wide config file.
+//
+// Description:
+// - On Windows, returns "%ProgramData%\file-manager\config.json".
+// - On Linux, returns "/etc/file-manager/config.json".
+//
+// Parameters: None
+//
+// Returns:
+// - string: The system config file path.
+//
+// Example Usage:
+// ```go
+// fmt.Println("System config:", SystemConfigPath())
+// ```
+func SystemConfigPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "file-manager", "config.json")
+	}
+	return "/etc/file-manager/config.json"
+}
+
+// UserConfigPath returns the path of the per-user config file.
+//
+// Description:
+// - Uses the platform user config directory (e.g., "%AppData%" on Windows, "~/.config" on Linux).
+// - Returns an empty string if the user config directory cannot be determined.
+//
+// Parameters: None
+//
+// Returns:
+// - string: The per-user config file path.
+//
+// Example Usage:
+// ```go
+// fmt.Println("User config:", UserConfigPath())
+// ```
+func UserConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "file-manager", "config.json")
+}
+
+// Load reads the system config file and then the per-user config file.
+//
+// Description:
+// - Missing config files are ignored.
+// - Values in the per-user file override values in the system file.
+// - If the FILE_MANAGER_CONFIG environment variable is set, that file is loaded last and must exist.
+//
+// Parameters: None
+//
+// Returns:
+// - Config: The merged configuration.
+// - error: An error if a config file exists but cannot be read or parsed.
+//
+// Example Usage:
+// ```go
+// cfg, err := Load()
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Loaded config from:", cfg.Sources)
+//	}
+//
+// ```
+func Load() (Config, error) {
+	var cfg Config
+
+	for _, path := range []string{SystemConfigPath(), UserConfigPath()} {
+		if path == "" {
+			continue
+		}
+		if err := cfg.LoadFile(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return cfg, err
+		}
+	}
+
+	if path := os.Getenv("FILE_MANAGER_CONFIG"); path != "" {
+		if err := cfg.LoadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// LoadFile merges a JSON config file into the configuration.
+//
+// Description:
+// - Values from the file override values already present in the configuration.
+// - Profiles are replaced as a whole when a profile with the same name is loaded again.
+// - The defaults section may only set the flags listed in GlobalFlags.
+//
+// Parameters:
+// - path (string): The config file to read.
+//
+// Returns:
+// - error: An error if the file cannot be read or parsed, or its defaults set a flag not in GlobalFlags. Missing files wrap os.ErrNotExist.
+//
+// Example Usage:
+// ```go
+// var cfg Config
+//
+//	if err := cfg.LoadFile("team-config.json"); err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func (cfg *Config) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var loaded Config
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	if cfg.Defaults == nil {
+		cfg.Defaults = map[string]string{}
+	}
+	for name, value := range loaded.Defaults {
+		if !GlobalFlags[name] {
+			return fmt.Errorf("config file %s: -%s cannot be set in defaults; set it under commands or in a profile", path, name)
+		}
+		cfg.Defaults[name] = value
+	}
+
+	if cfg.Commands == nil {
+		cfg.Commands = map[string]map[string]string{}
+	}
+	for command, flags := range loaded.Commands {
+		if cfg.Commands[command] == nil {
+			cfg.Commands[command] = map[string]string{}
+		}
+		for name, value := range flags {
+			cfg.Commands[command][name] = value
+		}
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	for name, profile := range loaded.Profiles {
+		cfg.Profiles[name] = profile
+	}
+
+	cfg.Sources = append(cfg.Sources, path)
+	return nil
+}
+
+// EffectiveFlags returns the flag values the configuration sets for a command.
+//
+// Description:
+// - Starts with the global defaults, then applies the command's defaults, then the profile's flags.
+// - Global defaults for flags not listed in GlobalFlags are ignored.
+// - Flags given on the command line are expected to override these values.
+//
+// Parameters:
+// - command (string): The command being run (e.g., "find").
+// - profileName (string): The profile to apply (use "" for none).
+//
+// Returns:
+// - map[string]string: Flag names mapped to their configured values.
+// - error: An error if the profile does not exist or belongs to a different command.
+//
+// Example Usage:
+// ```go
+// flags, err := cfg.EffectiveFlags("content", "weekly-log-sweep")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Configured flags:", flags)
+//	}
+//
+// ```
+func (cfg Config) EffectiveFlags(command, profileName string) (map[string]string, error) {
+	flags := map[string]string{}
+	for name, value := range cfg.Defaults {
+		if GlobalFlags[name] {
+			flags[name] = value
+		}
+	}
+	for name, value := range cfg.Commands[command] {
+		flags[name] = value
+	}
+
+	if profileName != "" {
+		profile, ok := cfg.Profiles[profileName]
+		if !ok {
+			return nil, fmt.Errorf("profile %s not found (available: %v)", profileName, cfg.ProfileNames())
+		}
+		if profile.Command != "" && profile.Command != command {
+			return nil, fmt.Errorf("profile %s is for the %s command, not %s", profileName, profile.Command, command)
+		}
+		for name, value := range profile.Flags {
+			flags[name] = value
+		}
+	}
+
+	return flags, nil
+}
+
+// ProfileNames returns the names of all loaded profiles in sorted order.
+func (cfg Config) ProfileNames() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
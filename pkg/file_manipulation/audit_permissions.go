@@ -0,0 +1,276 @@
+// This module builds on every platform, but its audits run only on platforms other than Windows (Linux and macOS).
+
+package file_manipulation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PermissionFinding is a risky permission or ownership state found by AuditPermissions.
+type PermissionFinding struct {
+	Path     string `json:"path"`
+	Severity string `json:"severity"` // "critical", "high", "medium" or "low"
+	Check    string `json:"check"`    // Short name of the check that fired (e.g., "world-writable")
+	Detail   string `json:"detail"`
+}
+
+// PermissionAuditOptions configures AuditPermissions.
+type PermissionAuditOptions struct {
+	SUIDAllowlistPath string   // File listing expected SUID/SGID binaries, one path per line (use "" to report all)
+	SystemPaths       []string // Directories treated as system paths (default: DefaultSystemPaths)
+}
+
+// DefaultSystemPaths are the directories where group-writable files are reported.
+var DefaultSystemPaths = []string{"/bin", "/sbin", "/usr", "/lib", "/lib32", "/lib64", "/etc", "/boot"}
+
+// severityRank orders severities from most to least severe.
+var severityRank = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+// AuditPermissions flags risky file permissions and ownership.
+//
+// Description:
+// - World-writable files (high, critical in system paths) and world-writable directories without the sticky bit (high).
+// - SUID and SGID binaries (medium/low), or critical when an allowlist is given and the binary is not on it.
+// - Files owned by a UID or GID that no longer exists (medium).
+// - Group-writable files in system paths (medium).
+// - Symbolic links and device files are skipped.
+// - The audit is Unix-only: Windows reports synthetic permission bits and no owners, so it returns an error there.
+//
+// Parameters:
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+// - options (PermissionAuditOptions): SUID allowlist and system paths.
+//
+// Returns:
+// - []PermissionFinding: The findings, most severe first.
+// - error: An error if the platform is Windows, the allowlist cannot be read or the search fails.
+//
+// Example Usage:
+// ```go
+// findings, err := AuditPermissions(false, "/", PermissionAuditOptions{SUIDAllowlistPath: "suid-allowlist.txt"})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, finding := range findings {
+//	        fmt.Println(finding.Severity, finding.Check, finding.Path)
+//	    }
+//	}
+//
+// ```
+func AuditPermissions(searchAllDrives bool, checkThisDisk string, options PermissionAuditOptions) ([]PermissionFinding, error) {
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+	var allowlist map[string]bool
+	if options.SUIDAllowlistPath != "" {
+		list, err := readPathList(options.SUIDAllowlistPath)
+		if err != nil {
+			return nil, err
+		}
+		allowlist = list
+	}
+	systemPaths := options.SystemPaths
+	if systemPaths == nil {
+		systemPaths = DefaultSystemPaths
+	}
+
+	var findings []PermissionFinding
+	owners := newOwnerCache()
+
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Auditing permissions in: %s\n", drive)
+		err := WalkFiles(drive, WalkOptions{}, func(path string, info os.FileInfo) error {
+			if info.IsDir() && virtualFilesystemPaths[path] {
+				return filepath.SkipDir
+			}
+			mode := info.Mode()
+			if !mode.IsRegular() && !mode.IsDir() {
+				return nil
+			}
+			ownership := getFileOwnership(info)
+			if !ownership.Known {
+				return nil // No POSIX metadata, so the permission bits are synthetic
+			}
+
+			inSystemPath := isUnderAny(path, systemPaths)
+			add := func(severity, check, detail string) {
+				findings = append(findings, PermissionFinding{Path: path, Severity: severity, Check: check, Detail: detail})
+			}
+
+			if mode.Perm()&0o002 != 0 {
+				switch {
+				case mode.IsDir() && mode&os.ModeSticky == 0:
+					add("high", "world-writable-dir", fmt.Sprintf("directory %s is world-writable without the sticky bit", mode))
+				case mode.IsRegular() && inSystemPath:
+					add("critical", "world-writable", fmt.Sprintf("system file %s is world-writable", mode))
+				case mode.IsRegular():
+					add("high", "world-writable", fmt.Sprintf("file %s is world-writable", mode))
+				}
+			}
+
+			if mode.IsRegular() && mode&(os.ModeSetuid|os.ModeSetgid) != 0 {
+				kind := "suid"
+				severity := "medium"
+				if mode&os.ModeSetuid == 0 {
+					kind, severity = "sgid", "low"
+				}
+				switch {
+				case allowlist == nil:
+					add(severity, kind, fmt.Sprintf("%s binary %s", strings.ToUpper(kind), mode))
+				case !allowlist[path]:
+					add("critical", kind+"-not-allowlisted", fmt.Sprintf("%s binary %s is not on the allowlist", strings.ToUpper(kind), mode))
+				}
+			}
+
+			if mode.IsRegular() && inSystemPath && mode.Perm()&0o020 != 0 && mode.Perm()&0o002 == 0 {
+				add("medium", "group-writable-system", fmt.Sprintf("system file %s is group-writable", mode))
+			}
+
+			if !owners.userExists(ownership.UID) {
+				add("medium", "orphaned-owner", fmt.Sprintf("owned by deleted UID %d", ownership.UID))
+			}
+			if !owners.groupExists(ownership.GID) {
+				add("medium", "orphaned-group", fmt.Sprintf("owned by deleted GID %d", ownership.GID))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if severityRank[findings[i].Severity] != severityRank[findings[j].Severity] {
+			return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+		}
+		return findings[i].Path < findings[j].Path
+	})
+	return findings, nil
+}
+
+// WriteSUIDAllowlist records the SUID and SGID binaries currently on disk as the new allowlist.
+//
+// Description:
+// - Searches for regular files with the SUID or SGID bit set and writes their paths, one per line.
+// - Use the file as PermissionAuditOptions.SUIDAllowlistPath to spot binaries added since.
+// - Like AuditPermissions, it is Unix-only.
+//
+// Parameters:
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+// - allowlistPath (string): The file to write.
+//
+// Returns:
+// - []string: The SUID and SGID binaries written to the allowlist.
+// - error: An error if the platform is Windows, the search fails or the file cannot be written.
+//
+// Example Usage:
+// ```go
+// binaries, err := WriteSUIDAllowlist(false, "/", "suid-allowlist.txt")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Allowlisted %d binaries.\n", len(binaries))
+//	}
+//
+// ```
+func WriteSUIDAllowlist(searchAllDrives bool, checkThisDisk, allowlistPath string) ([]string, error) {
+	if runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+	var binaries []string
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Searching for SUID/SGID binaries in: %s\n", drive)
+		err := WalkFiles(drive, WalkOptions{}, func(path string, info os.FileInfo) error {
+			if info.IsDir() && virtualFilesystemPaths[path] {
+				return filepath.SkipDir
+			}
+			if info.Mode().IsRegular() && info.Mode()&(os.ModeSetuid|os.ModeSetgid) != 0 {
+				binaries = append(binaries, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+	}
+
+	sort.Strings(binaries)
+	content := "# SUID/SGID allowlist written by file-manager perms-audit\n" + strings.Join(binaries, "\n") + "\n"
+	if err := os.WriteFile(allowlistPath, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write allowlist %s: %v", allowlistPath, err)
+	}
+	return binaries, nil
+}
+
+// readPathList reads a file of paths, one per line, ignoring blank lines and "#" comments.
+func readPathList(listPath string) (map[string]bool, error) {
+	file, err := os.Open(listPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", listPath, err)
+	}
+	defer file.Close()
+
+	paths := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths[filepath.Clean(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", listPath, err)
+	}
+	return paths, nil
+}
+
+// ownerCache remembers which UIDs and GIDs resolve to an existing user or group.
+type ownerCache struct {
+	users  map[int]bool
+	groups map[int]bool
+}
+
+func newOwnerCache() *ownerCache {
+	return &ownerCache{users: map[int]bool{}, groups: map[int]bool{}}
+}
+
+// userExists reports whether the UID belongs to a known user. Lookup failures other than
+// "unknown user" are treated as existing, to avoid false positives.
+func (cache *ownerCache) userExists(uid int) bool {
+	exists, ok := cache.users[uid]
+	if !ok {
+		_, err := user.LookupId(strconv.Itoa(uid))
+		var unknown user.UnknownUserIdError
+		exists = !errors.As(err, &unknown)
+		cache.users[uid] = exists
+	}
+	return exists
+}
+
+// groupExists reports whether the GID belongs to a known group. Lookup failures other than
+// "unknown group" are treated as existing, to avoid false positives.
+func (cache *ownerCache) groupExists(gid int) bool {
+	exists, ok := cache.groups[gid]
+	if !ok {
+		_, err := user.LookupGroupId(strconv.Itoa(gid))
+		var unknown user.UnknownGroupIdError
+		exists = !errors.As(err, &unknown)
+		cache.groups[gid] = exists
+	}
+	return exists
+}
@@ -0,0 +1,253 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BodyfileEntry is one line of a Sleuth Kit bodyfile (version 3 format).
+// Times are Unix seconds, with 0 meaning the time is not known.
+type BodyfileEntry struct {
+	MD5    string // "0" if not computed
+	Name   string // Path, with " -> target" appended for symbolic links
+	Inode  uint64
+	Mode   string // Mode string such as "r/rrwxr-xr-x"
+	UID    int
+	GID    int
+	Size   int64
+	Atime  int64
+	Mtime  int64
+	Ctime  int64
+	Crtime int64
+}
+
+// BodyfileOptions controls WriteBodyfile.
+type BodyfileOptions struct {
+	WithMD5 bool     // Hash regular files with MD5
+	Include []string // Only write files matching these patterns
+	Exclude []string // Skip files and directories matching these patterns
+}
+
+// String formats the entry as a bodyfile line: MD5|name|inode|mode|uid|gid|size|atime|mtime|ctime|crtime.
+func (entry BodyfileEntry) String() string {
+	return fmt.Sprintf("%s|%s|%d|%s|%d|%d|%d|%d|%d|%d|%d", entry.MD5, entry.Name, entry.Inode, entry.Mode,
+		entry.UID, entry.GID, entry.Size, entry.Atime, entry.Mtime, entry.Ctime, entry.Crtime)
+}
+
+// WriteBodyfile walks a directory and writes a bodyfile line for every entry under it.
+//
+// Description:
+// - Records the inode, mode, owner, size and access, modification, change and birth times of each file and directory.
+// - Birth time comes from statx on Linux and the creation time on Windows; times the platform does not report are written as 0.
+// - Symbolic links are not followed; their target is appended to the name as " -> target".
+// - Regular files are hashed with MD5 when options.WithMD5 is set; otherwise the MD5 field is "0".
+//
+// Parameters:
+// - root (string): The directory to walk.
+// - output (io.Writer): Where to write the bodyfile lines.
+// - options (BodyfileOptions): MD5 hashing and include/exclude patterns.
+//
+// Returns:
+// - int: The number of lines written.
+// - error: An error if the directory cannot be walked or the output cannot be written.
+//
+// Example Usage:
+// ```go
+// file, _ := os.Create("host1.body")
+// defer file.Close()
+// count, err := WriteBodyfile("/home", file, BodyfileOptions{WithMD5: true})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Wrote %d bodyfile lines.\n", count)
+//	}
+//
+// ```
+func WriteBodyfile(root string, output io.Writer, options BodyfileOptions) (int, error) {
+	writer := bufio.NewWriter(output)
+	count := 0
+
+	fmt.Printf("Writing bodyfile for: %s\n", root)
+	err := WalkFiles(root, WalkOptions{Include: options.Include, Exclude: options.Exclude}, func(path string, info os.FileInfo) error {
+		entry := newBodyfileEntry(path, info, options.WithMD5)
+		if _, err := fmt.Fprintln(writer, entry.String()); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("error processing directory %s: %v", root, err)
+	}
+	if err := writer.Flush(); err != nil {
+		return count, fmt.Errorf("failed to write bodyfile: %v", err)
+	}
+	return count, nil
+}
+
+// ParseBodyfile reads bodyfile lines written by WriteBodyfile or The Sleuth Kit.
+//
+// Description:
+// - Expects the 11-field version 3 format; blank lines and lines starting with "#" are skipped.
+// - Names containing "|" are kept intact by reading the fixed fields from both ends of the line.
+//
+// Parameters:
+// - input (io.Reader): The bodyfile contents.
+//
+// Returns:
+// - []BodyfileEntry: The parsed entries.
+// - error: An error naming the first malformed line.
+//
+// Example Usage:
+// ```go
+// file, _ := os.Open("host1.body")
+// defer file.Close()
+// entries, err := ParseBodyfile(file)
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Read %d entries.\n", len(entries))
+//	}
+//
+// ```
+func ParseBodyfile(input io.Reader) ([]BodyfileEntry, error) {
+	var entries []BodyfileEntry
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) < 11 {
+			return nil, fmt.Errorf("bodyfile line %d: expected 11 fields, found %d", lineNumber, len(fields))
+		}
+		// The name is the only field that may contain "|", so take the fixed fields from both ends.
+		tail := fields[len(fields)-9:]
+		entry := BodyfileEntry{
+			MD5:  fields[0],
+			Name: strings.Join(fields[1:len(fields)-9], "|"),
+			Mode: tail[1],
+		}
+
+		numbers := make([]int64, 0, 7)
+		for _, index := range []int{2, 3, 4, 5, 6, 7, 8} {
+			value, err := strconv.ParseInt(tail[index], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("bodyfile line %d: invalid number %q", lineNumber, tail[index])
+			}
+			numbers = append(numbers, value)
+		}
+		inode, err := strconv.ParseUint(tail[0], 10, 64)
+		if err != nil {
+			// Some tools write NTFS-style inode strings such as "1234-128-1"; keep the leading number.
+			inode, _ = strconv.ParseUint(strings.SplitN(tail[0], "-", 2)[0], 10, 64)
+		}
+		entry.Inode = inode
+		entry.UID, entry.GID, entry.Size = int(numbers[0]), int(numbers[1]), numbers[2]
+		entry.Atime, entry.Mtime, entry.Ctime, entry.Crtime = numbers[3], numbers[4], numbers[5], numbers[6]
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read bodyfile: %v", err)
+	}
+	return entries, nil
+}
+
+// newBodyfileEntry builds the bodyfile entry for a walked file.
+func newBodyfileEntry(path string, info os.FileInfo, withMD5 bool) BodyfileEntry {
+	ownership := getFileOwnership(info)
+	times := getFileTimes(path, info)
+	entry := BodyfileEntry{
+		MD5:    "0",
+		Name:   path,
+		Inode:  ownership.Inode,
+		Mode:   bodyfileMode(info.Mode()),
+		Size:   info.Size(),
+		Atime:  unixSeconds(times.Access),
+		Mtime:  unixSeconds(times.Modify),
+		Ctime:  unixSeconds(times.Change),
+		Crtime: unixSeconds(times.Birth),
+	}
+	if ownership.Known {
+		entry.UID, entry.GID = ownership.UID, ownership.GID
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		if target, err := os.Readlink(path); err == nil {
+			entry.Name = path + " -> " + target
+		}
+	case withMD5 && info.Mode().IsRegular():
+		if hash, err := GetFileHash(path, "md5"); err == nil {
+			entry.MD5 = hash
+		}
+	}
+	return entry
+}
+
+// bodyfileMode formats a file mode the way The Sleuth Kit does, e.g. "r/rrwxr-xr-x" or "d/drwxr-xr-x".
+func bodyfileMode(mode os.FileMode) string {
+	var kind byte
+	switch {
+	case mode.IsRegular():
+		kind = 'r'
+	case mode.IsDir():
+		kind = 'd'
+	case mode&os.ModeSymlink != 0:
+		kind = 'l'
+	case mode&os.ModeNamedPipe != 0:
+		kind = 'p'
+	case mode&os.ModeSocket != 0:
+		kind = 's'
+	case mode&os.ModeCharDevice != 0:
+		kind = 'c'
+	case mode&os.ModeDevice != 0:
+		kind = 'b'
+	default:
+		kind = '-'
+	}
+
+	perm := []byte("rwxrwxrwx")
+	for i := range perm {
+		if mode.Perm()&(1<<uint(8-i)) == 0 {
+			perm[i] = '-'
+		}
+	}
+	special := func(index int, set bool, lower, upper byte) {
+		if !set {
+			return
+		}
+		if perm[index] == 'x' {
+			perm[index] = lower
+		} else {
+			perm[index] = upper
+		}
+	}
+	special(2, mode&os.ModeSetuid != 0, 's', 'S')
+	special(5, mode&os.ModeSetgid != 0, 's', 'S')
+	special(8, mode&os.ModeSticky != 0, 't', 'T')
+
+	return string([]byte{kind, '/', kind}) + string(perm)
+}
+
+// unixSeconds converts a time to Unix seconds, returning 0 for the zero time.
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
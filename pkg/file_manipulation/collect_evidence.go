@@ -0,0 +1,577 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	evidenceManifestName  = "manifest.json"
+	evidenceSignatureName = "manifest.json.sig"
+)
+
+// EvidenceFile describes one collected file in an evidence manifest.
+type EvidenceFile struct {
+	OriginalPath string      `json:"original_path"`
+	ArchivePath  string      `json:"archive_path"`
+	Size         int64       `json:"size"`
+	Mode         os.FileMode `json:"mode"`
+	ModTime      time.Time   `json:"mtime"`
+	UID          int         `json:"uid"` // -1 if not available on the platform
+	GID          int         `json:"gid"` // -1 if not available on the platform
+	SHA256       string      `json:"sha256"`
+}
+
+// EvidenceManifest records what was collected, when, where and by whom.
+type EvidenceManifest struct {
+	CollectedAt time.Time      `json:"collected_at"`
+	Hostname    string         `json:"hostname"`
+	Collector   string         `json:"collector"`
+	Files       []EvidenceFile `json:"files"`
+	Errors      []string       `json:"errors,omitempty"` // Files that matched but could not be collected
+}
+
+// EvidenceVerification is the result of checking an evidence archive against its manifest.
+type EvidenceVerification struct {
+	Manifest   EvidenceManifest
+	Verified   []string // Archive paths whose hash matches the manifest
+	Mismatched []string // Archive paths whose hash differs from the manifest
+	Missing    []string // Manifest entries not present in the archive
+	Unexpected []string // Archive entries not listed in the manifest
+	Signature  string   // "valid", "invalid", "unsigned" or "not checked"
+}
+
+// OK reports whether every file matched and, if a key was given, the manifest carries a valid signature.
+func (verification EvidenceVerification) OK() bool {
+	return len(verification.Mismatched) == 0 && len(verification.Missing) == 0 && len(verification.Unexpected) == 0 &&
+		(verification.Signature == "valid" || verification.Signature == "not checked")
+}
+
+// CollectEvidence packages files into a zip or tar.gz archive with a hashed manifest.
+//
+// Description:
+// - Stores each file under "files/" followed by its original absolute path.
+// - Preserves modification times, permissions and, where the platform exposes them, owner and group.
+// - Writes "manifest.json" with the SHA-256 of every file, the collection time, hostname and collecting user.
+// - If a signing key is given, writes an ed25519 signature of the manifest to "manifest.json.sig".
+// - The archive format is chosen from the extension: ".zip", or ".tar.gz"/".tgz".
+// - Each file is staged in a temporary file next to the archive while it is hashed, so a file that changes during collection is stored as it was read.
+// - Staging never writes to the system temp directory, which may be on the disk being examined; only the archive's directory receives data.
+// - Files that cannot be read are listed in the manifest's Errors; if the archive itself cannot be written, the partial archive is removed.
+//
+// Parameters:
+// - files ([]string): The files to collect. Non-regular files are skipped.
+// - archivePath (string): The archive to create.
+// - signingKeyPath (string): A PEM (PKCS#8) ed25519 private key, e.g. from `openssl genpkey -algorithm ed25519` (use "" to skip signing).
+//
+// Returns:
+// - EvidenceManifest: The manifest written to the archive.
+// - error: An error if the archive cannot be written or the key cannot be loaded.
+//
+// Example Usage:
+// ```go
+// manifest, err := CollectEvidence([]string{"/tmp/dropper.sh"}, "case-42.tar.gz", "collector.pem")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Collected %d files.\n", len(manifest.Files))
+//	}
+//
+// ```
+func CollectEvidence(files []string, archivePath, signingKeyPath string) (EvidenceManifest, error) {
+	hostname, _ := os.Hostname()
+	return collectEvidence(files, openEvidenceFile, archivePath, signingKeyPath, hostname)
+}
+
+// CollectEvidenceFS is CollectEvidence for files in any fs.FS, such as a mounted image or a MemFS.
+//
+// Description:
+// - Files are stored under "files/" followed by their fsys name, and the manifest's original paths are fsys names.
+// - The archive is still written to the local disk, and the manifest's Hostname is left empty.
+//
+// Parameters:
+// - fsys (fs.FS): The file system to collect from.
+// - names ([]string): The fsys names of the files to collect (e.g., "tmp/dropper.sh").
+// - archivePath (string): The archive to create on the local disk.
+// - signingKeyPath (string): A PEM (PKCS#8) ed25519 private key (use "" to skip signing).
+//
+// Returns:
+// - EvidenceManifest: The manifest written to the archive.
+// - error: An error if the archive cannot be written or the key cannot be loaded.
+//
+// Example Usage:
+// ```go
+// manifest, err := CollectEvidenceFS(os.DirFS("/mnt/evidence"), []string{"tmp/dropper.sh"}, "case-42.tar.gz", "")
+// ```
+func CollectEvidenceFS(fsys fs.FS, names []string, archivePath, signingKeyPath string) (EvidenceManifest, error) {
+	open := func(name string) (fs.File, string, error) {
+		file, err := fsys.Open(name)
+		return file, name, err
+	}
+	return collectEvidence(names, open, archivePath, signingKeyPath, "")
+}
+
+// openEvidenceFile opens a file on the local disk and returns it with its absolute path.
+func openEvidenceFile(filePath string) (fs.File, string, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	file, err := os.Open(absPath)
+	return file, absPath, err
+}
+
+// collectEvidence writes the files, opened with open, to an evidence archive.
+// open returns each file and the original path to record for it.
+func collectEvidence(files []string, open func(name string) (fs.File, string, error), archivePath, signingKeyPath, hostname string) (EvidenceManifest, error) {
+	var signingKey ed25519.PrivateKey
+	if signingKeyPath != "" {
+		key, err := loadEd25519PrivateKey(signingKeyPath)
+		if err != nil {
+			return EvidenceManifest{}, err
+		}
+		signingKey = key
+	}
+
+	manifest := EvidenceManifest{CollectedAt: time.Now().UTC(), Hostname: hostname}
+	if current, err := user.Current(); err == nil {
+		manifest.Collector = current.Username
+	}
+
+	output, err := os.Create(archivePath)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to create archive %s: %v", archivePath, err)
+	}
+	complete := false
+	defer func() {
+		output.Close()
+		if !complete {
+			os.Remove(archivePath) // Do not leave a truncated archive behind
+		}
+	}()
+
+	writer, err := newEvidenceWriter(output, archivePath)
+	if err != nil {
+		return manifest, err
+	}
+
+	for _, filePath := range files {
+		fmt.Printf("Collecting: %s\n", filePath)
+		entry, info, staged, err := stageEvidenceFile(open, filePath, filepath.Dir(archivePath))
+		if err != nil {
+			manifest.Errors = append(manifest.Errors, fmt.Sprintf("%s: %v", filePath, err))
+			continue
+		}
+		err = writer.addFile(entry, info, staged)
+		staged.Close()
+		os.Remove(staged.Name())
+		if err != nil {
+			return manifest, fmt.Errorf("failed to write %s to archive %s: %v", filePath, archivePath, err)
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, fmt.Errorf("failed to encode manifest: %v", err)
+	}
+	if err := writer.addBytes(evidenceManifestName, manifestData, manifest.CollectedAt); err != nil {
+		return manifest, fmt.Errorf("failed to write manifest: %v", err)
+	}
+	if signingKey != nil {
+		signature := base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, manifestData))
+		if err := writer.addBytes(evidenceSignatureName, []byte(signature+"\n"), manifest.CollectedAt); err != nil {
+			return manifest, fmt.Errorf("failed to write manifest signature: %v", err)
+		}
+	}
+
+	if err := writer.close(); err != nil {
+		return manifest, fmt.Errorf("failed to finish archive %s: %v", archivePath, err)
+	}
+	if err := output.Close(); err != nil {
+		return manifest, fmt.Errorf("failed to finish archive %s: %v", archivePath, err)
+	}
+	complete = true
+	return manifest, nil
+}
+
+// VerifyEvidenceArchive checks an evidence archive against its manifest.
+//
+// Description:
+// - Re-hashes every file in the archive and compares it with the SHA-256 recorded in the manifest.
+// - Reports mismatched, missing and unexpected entries.
+// - If a key is given, verifies the manifest's ed25519 signature. A private key file may be given in place of a public key.
+//
+// Parameters:
+// - archivePath (string): The zip or tar.gz archive to verify.
+// - publicKeyPath (string): A PEM ed25519 public key (use "" to skip the signature check).
+//
+// Returns:
+// - EvidenceVerification: The verification results.
+// - error: An error if the archive or key cannot be read or the manifest is missing.
+//
+// Example Usage:
+// ```go
+// result, err := VerifyEvidenceArchive("case-42.tar.gz", "collector.pub.pem")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Archive intact:", result.OK())
+//	}
+//
+// ```
+func VerifyEvidenceArchive(archivePath, publicKeyPath string) (EvidenceVerification, error) {
+	verification := EvidenceVerification{Signature: "not checked"}
+
+	hashes := map[string]string{}
+	var manifestData, signatureData []byte
+	err := readEvidenceArchive(archivePath, func(name string, reader io.Reader) error {
+		switch name {
+		case evidenceManifestName:
+			data, err := io.ReadAll(reader)
+			manifestData = data
+			return err
+		case evidenceSignatureName:
+			data, err := io.ReadAll(reader)
+			signatureData = data
+			return err
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, reader); err != nil {
+			return err
+		}
+		hashes[name] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return verification, fmt.Errorf("failed to read archive %s: %v", archivePath, err)
+	}
+	if manifestData == nil {
+		return verification, fmt.Errorf("archive %s has no %s", archivePath, evidenceManifestName)
+	}
+	if err := json.Unmarshal(manifestData, &verification.Manifest); err != nil {
+		return verification, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	listed := map[string]bool{}
+	for _, file := range verification.Manifest.Files {
+		listed[file.ArchivePath] = true
+		actual, ok := hashes[file.ArchivePath]
+		switch {
+		case !ok:
+			verification.Missing = append(verification.Missing, file.ArchivePath)
+		case actual != file.SHA256:
+			verification.Mismatched = append(verification.Mismatched, file.ArchivePath)
+		default:
+			verification.Verified = append(verification.Verified, file.ArchivePath)
+		}
+	}
+	for name := range hashes {
+		if !listed[name] {
+			verification.Unexpected = append(verification.Unexpected, name)
+		}
+	}
+	sort.Strings(verification.Unexpected)
+
+	if publicKeyPath != "" {
+		publicKey, err := loadEd25519PublicKey(publicKeyPath)
+		if err != nil {
+			return verification, err
+		}
+		signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signatureData)))
+		switch {
+		case signatureData == nil:
+			verification.Signature = "unsigned"
+		case err == nil && ed25519.Verify(publicKey, manifestData, signature):
+			verification.Signature = "valid"
+		default:
+			verification.Signature = "invalid"
+		}
+	}
+
+	return verification, nil
+}
+
+// evidenceWriter writes files into a zip or tar.gz archive.
+type evidenceWriter struct {
+	zipWriter  *zip.Writer
+	tarWriter  *tar.Writer
+	gzipWriter *gzip.Writer
+}
+
+// newEvidenceWriter picks the archive format from the archive file name.
+func newEvidenceWriter(output io.Writer, archivePath string) (*evidenceWriter, error) {
+	name := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return &evidenceWriter{zipWriter: zip.NewWriter(output)}, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		gzipWriter := gzip.NewWriter(output)
+		return &evidenceWriter{gzipWriter: gzipWriter, tarWriter: tar.NewWriter(gzipWriter)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format for %s (use .zip, .tar.gz or .tgz)", archivePath)
+	}
+}
+
+// stageEvidenceFile opens a file with open and copies it to a temporary file in stagingDir, hashing it on the way.
+// Archive headers need the size up front, and a file that changes while it is read would otherwise corrupt the archive.
+// The caller must close and remove the staged file.
+func stageEvidenceFile(open func(name string) (fs.File, string, error), filePath, stagingDir string) (EvidenceFile, os.FileInfo, *os.File, error) {
+	file, originalPath, err := open(filePath)
+	if err != nil {
+		return EvidenceFile{}, nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return EvidenceFile{}, nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return EvidenceFile{}, nil, nil, fmt.Errorf("not a regular file")
+	}
+
+	staged, err := os.CreateTemp(stagingDir, ".evidence-*.tmp")
+	if err != nil {
+		return EvidenceFile{}, nil, nil, fmt.Errorf("failed to create staging file: %v", err)
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(staged, h), throttleReader(file))
+	if err == nil {
+		_, err = staged.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		staged.Close()
+		os.Remove(staged.Name())
+		return EvidenceFile{}, nil, nil, fmt.Errorf("failed to copy file: %v", err)
+	}
+
+	ownership := getFileOwnership(info)
+	entry := EvidenceFile{
+		OriginalPath: originalPath,
+		ArchivePath:  evidenceArchivePath(originalPath),
+		Size:         size, // What was actually read, even if the file changed since the Stat
+		Mode:         info.Mode(),
+		ModTime:      info.ModTime().UTC(),
+		UID:          ownership.UID,
+		GID:          ownership.GID,
+		SHA256:       hex.EncodeToString(h.Sum(nil)),
+	}
+	return entry, info, staged, nil
+}
+
+// addFile writes a staged file into the archive. Any error leaves the archive unusable.
+func (writer *evidenceWriter) addFile(entry EvidenceFile, info os.FileInfo, staged io.Reader) error {
+	var destination io.Writer
+	if writer.zipWriter != nil {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = entry.ArchivePath
+		header.Method = zip.Deflate
+		header.UncompressedSize64 = uint64(entry.Size)
+		if entry.UID >= 0 && entry.GID >= 0 {
+			header.Extra = append(header.Extra, zipUnixOwnerExtra(entry.UID, entry.GID)...)
+		}
+		destination, err = writer.zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+	} else {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = entry.ArchivePath
+		header.Size = entry.Size
+		header.Format = tar.FormatPAX
+		if err := writer.tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		destination = writer.tarWriter
+	}
+
+	_, err := io.CopyN(destination, staged, entry.Size)
+	return err
+}
+
+// addBytes writes an in-memory file (such as the manifest) into the archive.
+func (writer *evidenceWriter) addBytes(name string, data []byte, modTime time.Time) error {
+	if writer.zipWriter != nil {
+		destination, err := writer.zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+		if err != nil {
+			return err
+		}
+		_, err = destination.Write(data)
+		return err
+	}
+
+	header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := writer.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := writer.tarWriter.Write(data)
+	return err
+}
+
+// close flushes the archive.
+func (writer *evidenceWriter) close() error {
+	if writer.zipWriter != nil {
+		return writer.zipWriter.Close()
+	}
+	if err := writer.tarWriter.Close(); err != nil {
+		return err
+	}
+	return writer.gzipWriter.Close()
+}
+
+// readEvidenceArchive calls readFn for every regular file in a zip or tar.gz archive.
+func readEvidenceArchive(archivePath string, readFn func(name string, reader io.Reader) error) error {
+	name := strings.ToLower(archivePath)
+	if strings.HasSuffix(name, ".zip") {
+		archive, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		for _, file := range archive.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			reader, err := file.Open()
+			if err != nil {
+				return err
+			}
+			err = readFn(file.Name, reader)
+			reader.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := readFn(header.Name, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// evidenceArchivePath maps an absolute path to its location inside the archive,
+// e.g. "/etc/passwd" to "files/etc/passwd" and "C:\Users\a.txt" to "files/C/Users/a.txt".
+func evidenceArchivePath(absPath string) string {
+	volume := filepath.VolumeName(absPath)
+	rest := filepath.ToSlash(strings.TrimPrefix(absPath, volume))
+	volume = strings.Trim(strings.ReplaceAll(filepath.ToSlash(volume), ":", ""), "/")
+	return path.Join("files", volume, rest)
+}
+
+// zipUnixOwnerExtra builds the Info-ZIP "new Unix" extra field (0x7875) recording the owner and group.
+func zipUnixOwnerExtra(uid, gid int) []byte {
+	extra := make([]byte, 4, 15)
+	binary.LittleEndian.PutUint16(extra[0:2], 0x7875)
+	binary.LittleEndian.PutUint16(extra[2:4], 11)
+	extra = append(extra, 1, 4)
+	extra = binary.LittleEndian.AppendUint32(extra, uint32(uid))
+	extra = append(extra, 4)
+	extra = binary.LittleEndian.AppendUint32(extra, uint32(gid))
+	return extra
+}
+
+// loadEd25519PrivateKey reads a PEM encoded PKCS#8 ed25519 private key.
+func loadEd25519PrivateKey(keyPath string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %v", keyPath, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", keyPath)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %v", keyPath, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an ed25519 key", keyPath)
+	}
+	return privateKey, nil
+}
+
+// loadEd25519PublicKey reads a PEM encoded ed25519 public key, or derives it from a private key file.
+func loadEd25519PublicKey(keyPath string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %v", keyPath, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s is not PEM encoded", keyPath)
+	}
+	if block.Type == "PRIVATE KEY" {
+		privateKey, err := loadEd25519PrivateKey(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		return privateKey.Public().(ed25519.PublicKey), nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %v", keyPath, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an ed25519 key", keyPath)
+	}
+	return publicKey, nil
+}
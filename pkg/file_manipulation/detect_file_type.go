@@ -0,0 +1,342 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileType describes a file format identified from its leading bytes.
+type FileType struct {
+	Name       string   // Short format name (e.g., "ELF", "PE", "PNG"); "Unknown" when no signature matched
+	Extensions []string // Extensions normally used for the format; "" means no extension is expected
+}
+
+// FileTypeResult is the detected type of a single file.
+type FileTypeResult struct {
+	Path         string
+	DetectedType string
+	Extension    string
+	Mismatch     bool // True when the extension is not one normally used for the detected type
+}
+
+// unknownFileType is returned when no signature matches.
+var unknownFileType = FileType{Name: "Unknown"}
+
+// fileSignatures maps leading byte sequences to the file type they identify.
+var fileSignatures = []struct {
+	magic    []byte
+	fileType FileType
+}{
+	{[]byte("\x7fELF"), FileType{"ELF", []string{"", ".so", ".o", ".ko", ".elf", ".bin", ".out", ".axf", ".prx", ".mod"}}},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCE}, machOFileType},
+	{[]byte{0xFE, 0xED, 0xFA, 0xCF}, machOFileType},
+	{[]byte{0xCE, 0xFA, 0xED, 0xFE}, machOFileType},
+	{[]byte{0xCF, 0xFA, 0xED, 0xFE}, machOFileType},
+	{[]byte("PK\x03\x04"), zipFileType},
+	{[]byte("PK\x05\x06"), zipFileType},
+	{[]byte("PK\x07\x08"), zipFileType},
+	{[]byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, FileType{"OLE", []string{".doc", ".xls", ".ppt", ".msi", ".msg", ".msp", ".vsd", ".pub", ".dot", ".xlt", ".pps"}}},
+	{[]byte("%PDF-"), FileType{"PDF", []string{".pdf", ".ai"}}},
+	{[]byte("\x89PNG\r\n\x1a\n"), FileType{"PNG", []string{".png", ".apng"}}},
+	{[]byte{0xFF, 0xD8, 0xFF}, FileType{"JPEG", []string{".jpg", ".jpeg", ".jpe", ".jfif"}}},
+	{[]byte("GIF87a"), gifFileType},
+	{[]byte("GIF89a"), gifFileType},
+	{[]byte{0x1F, 0x8B}, FileType{"GZIP", []string{".gz", ".tgz", ".gzip", ".svgz", ".emz"}}},
+	{[]byte("7z\xBC\xAF\x27\x1C"), FileType{"7-Zip", []string{".7z"}}},
+	{[]byte("\xFD7zXZ\x00"), FileType{"XZ", []string{".xz", ".txz"}}},
+	{[]byte("BZh"), FileType{"BZIP2", []string{".bz2", ".tbz", ".tbz2"}}},
+	{[]byte{0x28, 0xB5, 0x2F, 0xFD}, FileType{"Zstandard", []string{".zst", ".tzst"}}},
+	{[]byte("Rar!\x1A\x07"), FileType{"RAR", []string{".rar"}}},
+	{[]byte("MSCF"), FileType{"CAB", []string{".cab", ".msu"}}},
+}
+
+// compressedFileTypes lists formats whose contents are compressed by nature, so high entropy is expected.
+var compressedFileTypes = map[string]bool{
+	"ZIP": true, "PNG": true, "JPEG": true, "GIF": true, "GZIP": true, "PDF": true,
+	"7-Zip": true, "XZ": true, "BZIP2": true, "Zstandard": true, "RAR": true, "CAB": true,
+}
+
+var (
+	machOFileType = FileType{"Mach-O", []string{"", ".dylib", ".bundle", ".o", ".so"}}
+	zipFileType   = FileType{"ZIP", []string{".zip", ".jar", ".war", ".ear", ".apk", ".aar", ".ipa", ".docx", ".docm", ".xlsx", ".xlsm", ".pptx", ".pptm", ".odt", ".ods", ".odp", ".epub", ".whl", ".nupkg", ".vsix", ".xpi", ".crx", ".kmz", ".appx", ".msix"}}
+	gifFileType   = FileType{"GIF", []string{".gif"}}
+	peFileType    = FileType{"PE", []string{".exe", ".dll", ".sys", ".scr", ".com", ".ocx", ".cpl", ".efi", ".drv", ".mui", ".node", ".pyd", ".winmd", ".ax", ".acm", ".tsp"}}
+	dosFileType   = FileType{"MZ/DOS", []string{".exe", ".com", ".ovl", ".sys", ".drv"}}
+	scriptType    = FileType{"Script", []string{"", ".sh", ".bash", ".zsh", ".ksh", ".csh", ".py", ".pl", ".rb", ".js", ".mjs", ".php", ".awk", ".tcl", ".lua", ".cgi", ".command", ".run"}}
+	javaClassType = FileType{"Java class", []string{".class"}}
+)
+
+// DetectFileType identifies a file's format from its leading bytes.
+//
+// Description:
+// - Reads the first bytes of the file and compares them against known signatures.
+// - Recognizes ELF, PE, MZ/DOS (an "MZ" header without a PE header), Mach-O, ZIP (including Office Open XML), OLE, PDF, PNG, JPEG, GIF and shebang scripts.
+// - Also recognizes compressed archives: gzip, 7-Zip, xz, bzip2, Zstandard, RAR and CAB.
+// - Returns a FileType named "Unknown" when no signature matches.
+//
+// Parameters:
+// - path (string): The file to inspect.
+//
+// Returns:
+// - FileType: The detected file type.
+// - error: An error if the file cannot be read.
+//
+// Example Usage:
+// ```go
+// fileType, err := DetectFileType("/tmp/invoice.jpg")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Detected type:", fileType.Name)
+//	}
+//
+// ```
+func DetectFileType(path string) (FileType, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return unknownFileType, fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	return detectFileTypeFromReader(file, path)
+}
+
+// DetectFileTypeFS is DetectFileType for a file in any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Parameters:
+// - fsys (fs.FS): The file system containing the file.
+// - name (string): The fsys name of the file (e.g., "uploads/invoice.pdf").
+//
+// Returns:
+// - FileType: The detected file type.
+// - error: An error if the file cannot be read.
+//
+// Example Usage:
+// ```go
+// fileType, err := DetectFileTypeFS(os.DirFS("/mnt/evidence"), "home/user/invoice.pdf")
+// ```
+func DetectFileTypeFS(fsys fs.FS, name string) (FileType, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return unknownFileType, fmt.Errorf("failed to open file %s: %v", name, err)
+	}
+	defer file.Close()
+
+	return detectFileTypeFromReader(file, name)
+}
+
+// detectFileTypeFromReader reads the header of an open file, named path in errors, and detects its type.
+func detectFileTypeFromReader(file io.Reader, path string) (FileType, error) {
+	// Large enough to hold the DOS stub of most PE files, so the PE header offset can be followed
+	header := make([]byte, 1024)
+	n, err := io.ReadFull(throttleReader(file), header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return unknownFileType, fmt.Errorf("failed to read file %s: %v", path, err)
+	}
+
+	return detectFileTypeFromHeader(header[:n]), nil
+}
+
+// detectFileTypeFromHeader matches the leading bytes of a file against the known signatures.
+func detectFileTypeFromHeader(header []byte) FileType {
+	if bytes.HasPrefix(header, []byte("#!")) {
+		return scriptType
+	}
+
+	// 0xCAFEBABE is shared by Mach-O universal binaries and Java class files; universal
+	// binaries store a small architecture count where class files store their version.
+	if bytes.HasPrefix(header, []byte{0xCA, 0xFE, 0xBA, 0xBE}) && len(header) >= 8 {
+		if binary.BigEndian.Uint32(header[4:8]) < 40 {
+			return machOFileType
+		}
+		return javaClassType
+	}
+
+	if bytes.HasPrefix(header, []byte("MZ")) {
+		if hasPEHeader(header) {
+			return peFileType
+		}
+		return dosFileType
+	}
+
+	for _, signature := range fileSignatures {
+		if bytes.HasPrefix(header, signature.magic) {
+			return signature.fileType
+		}
+	}
+	return unknownFileType
+}
+
+// hasPEHeader reports whether an "MZ" header's e_lfanew field, at offset 0x3C, points to a "PE\0\0"
+// signature within the header. Text files that happen to start with "MZ" have no such signature.
+func hasPEHeader(header []byte) bool {
+	if len(header) < 0x40 {
+		return false
+	}
+	offset := binary.LittleEndian.Uint32(header[0x3C:0x40])
+	if offset < 0x40 || uint64(offset)+4 > uint64(len(header)) {
+		return false
+	}
+	return bytes.Equal(header[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// MatchesExtension reports whether the file name uses an extension normally used for this type.
+// Unknown types match every extension.
+func (fileType FileType) MatchesExtension(path string) bool {
+	if fileType.Name == unknownFileType.Name {
+		return true
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	ext := filepath.Ext(name)
+	for _, expected := range fileType.Extensions {
+		if ext == expected {
+			return true
+		}
+	}
+
+	// Versioned shared libraries such as libc.so.6 carry the version as their extension.
+	if fileType.Name == "ELF" && strings.Contains(name, ".so.") {
+		return true
+	}
+	return false
+}
+
+// IsCompressed reports whether the format stores compressed data by nature (e.g., ZIP, JPEG, gzip),
+// so its contents are expected to have high entropy.
+func (fileType FileType) IsCompressed() bool {
+	return compressedFileTypes[fileType.Name]
+}
+
+// GetFileTypes detects the type of files matching a pattern and flags extension mismatches.
+//
+// Description:
+// - Searches for files matching a pattern in all drives or a specific directory.
+// - Detects each file's type from its leading bytes.
+// - Marks files whose extension is not one normally used for the detected type (e.g., a PE named .jpg).
+//
+// Parameters:
+// - filesToFind (string): The pattern of files to inspect (e.g., "*").
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+//
+// Returns:
+// - []FileTypeResult: The detected type of each matching file.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// results, err := GetFileTypes("*", false, "/home/user/Downloads")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, result := range results {
+//	        if result.Mismatch {
+//	            fmt.Printf("%s looks like %s\n", result.Path, result.DetectedType)
+//	        }
+//	    }
+//	}
+//
+// ```
+func GetFileTypes(filesToFind string, searchAllDrives bool, checkThisDisk string) ([]FileTypeResult, error) {
+	var results []FileTypeResult
+
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Detecting file types in: %s\n", drive)
+		driveResults, err := getFileTypes(os.DirFS(drive), osPathFunc(drive), filesToFind)
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+		results = append(results, driveResults...)
+	}
+
+	return results, nil
+}
+
+// GetFileTypesFS is GetFileTypes for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; use fs.Sub to search a subdirectory.
+// - Result paths are fsys names (slash-separated, e.g., "Downloads/invoice.jpg").
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - filesToFind (string): The pattern of files to inspect (e.g., "*").
+//
+// Returns:
+// - []FileTypeResult: The detected type of each matching file.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// results, err := GetFileTypesFS(os.DirFS("/mnt/evidence/home/user"), "*")
+// ```
+func GetFileTypesFS(fsys fs.FS, filesToFind string) ([]FileTypeResult, error) {
+	return getFileTypes(fsys, nil, filesToFind)
+}
+
+// getFileTypes detects the type of the files in fsys matching filesToFind.
+func getFileTypes(fsys fs.FS, toPath func(name string) string, filesToFind string) ([]FileTypeResult, error) {
+	var results []FileTypeResult
+	err := walkFS(fsys, ".", toPath, WalkOptions{Include: []string{filesToFind}}, func(name, path string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		fileType, err := DetectFileTypeFS(fsys, name)
+		if err != nil {
+			return nil
+		}
+		results = append(results, FileTypeResult{
+			Path:         path,
+			DetectedType: fileType.Name,
+			Extension:    filepath.Ext(path),
+			Mismatch:     !fileType.MatchesExtension(path),
+		})
+		return nil
+	})
+	return results, err
+}
+
+// FilterFilesByDetectedType keeps only the files whose detected type has the given name.
+//
+// Description:
+// - Detects the type of each file from its leading bytes and compares the name case-insensitively.
+// - Files that cannot be read are dropped.
+//
+// Parameters:
+// - files ([]string): The file paths to filter.
+// - typeName (string): The detected type to keep (e.g., "ELF", "PE", "ZIP").
+//
+// Returns:
+// - []string: The files whose detected type matches.
+//
+// Example Usage:
+// ```go
+// executables := FilterFilesByDetectedType(files, "PE")
+// fmt.Println("PE files:", executables)
+// ```
+func FilterFilesByDetectedType(files []string, typeName string) []string {
+	var matched []string
+	for _, path := range files {
+		fileType, err := DetectFileType(path)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(fileType.Name, typeName) {
+			matched = append(matched, path)
+		}
+	}
+	return matched
+}
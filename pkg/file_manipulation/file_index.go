@@ -0,0 +1,440 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IndexEntry is a single file or directory recorded in a FileIndex.
+type IndexEntry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Mode    os.FileMode
+	Hash    string // SHA-256 of the contents, if the index was built with hashes
+}
+
+// IndexedDirectory records a directory's modification time and the names of its children,
+// so unchanged directories can be skipped when the index is updated.
+type IndexedDirectory struct {
+	ModTime  time.Time
+	Children []string
+}
+
+// FileIndex is an on-disk index of file metadata used for fast repeated searches.
+type FileIndex struct {
+	Roots       []string
+	WithHashes  bool
+	UpdatedAt   time.Time
+	Entries     map[string]IndexEntry
+	Directories map[string]IndexedDirectory
+}
+
+// IndexQuery selects entries from a FileIndex. Zero values are ignored.
+type IndexQuery struct {
+	Pattern        string    // File name pattern (e.g., "*.log")
+	Under          []string  // Only return entries below one of these directories
+	MinSize        int64     // Minimum size in bytes
+	MaxSize        int64     // Maximum size in bytes
+	ModifiedAfter  time.Time // Only return entries modified after this time
+	ModifiedBefore time.Time // Only return entries modified before this time
+}
+
+// IndexUpdateStats describes the work done by an index build or update.
+type IndexUpdateStats struct {
+	Entries            int // Total entries in the index
+	DirectoriesScanned int // Directories that were re-read from disk
+	DirectoriesReused  int // Directories whose cached listing was reused
+}
+
+// virtualFilesystemPaths are virtual filesystems that tree scans never descend into.
+var virtualFilesystemPaths = map[string]bool{"/proc": true, "/sys": true}
+
+// DefaultIndexPath returns the default location of the file index.
+//
+// Description:
+// - Uses the platform user cache directory (e.g., "%LocalAppData%" on Windows, "~/.cache" on Linux).
+// - Falls back to the system temp directory if the cache directory cannot be determined.
+//
+// Parameters: None
+//
+// Returns:
+// - string: The default index file path.
+//
+// Example Usage:
+// ```go
+// fmt.Println("Index:", DefaultIndexPath())
+// ```
+func DefaultIndexPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "file-manager", "index.gob")
+}
+
+// BuildFileIndex walks one or more directories and writes a new index.
+//
+// Description:
+// - Records path, size, modification time and mode of every file and directory.
+// - Optionally records the SHA-256 hash of every regular file.
+// - Skips virtual filesystems such as /proc and /sys.
+// - Replaces any existing index at indexPath.
+//
+// Parameters:
+// - indexPath (string): Where to write the index (use "" for DefaultIndexPath()).
+// - searchAllDrives (bool): Whether to index all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to index (ignored if searchAllDrives is true).
+// - withHashes (bool): Whether to hash file contents.
+//
+// Returns:
+// - IndexUpdateStats: The number of entries and directories scanned.
+// - error: An error if the index cannot be written.
+//
+// Example Usage:
+// ```go
+// stats, err := BuildFileIndex("", false, "/home", false)
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Indexed %d entries.\n", stats.Entries)
+//	}
+//
+// ```
+func BuildFileIndex(indexPath string, searchAllDrives bool, checkThisDisk string, withHashes bool) (IndexUpdateStats, error) {
+	var roots []string
+	for _, root := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return IndexUpdateStats{}, fmt.Errorf("failed to resolve %s: %v", root, err)
+		}
+		roots = append(roots, absRoot)
+	}
+
+	index := &FileIndex{Roots: roots, WithHashes: withHashes}
+	stats := index.refresh(nil)
+	return stats, index.Save(indexPath)
+}
+
+// UpdateFileIndex incrementally refreshes an existing index.
+//
+// Description:
+// - Re-reads only directories whose modification time changed since the last update.
+// - Directories with an unchanged modification time reuse their cached listing.
+// - Changes to a file's contents that do not touch its directory are picked up by the next full build.
+//
+// Parameters:
+// - indexPath (string): The index to update (use "" for DefaultIndexPath()).
+//
+// Returns:
+// - IndexUpdateStats: The number of entries and directories scanned or reused.
+// - error: An error if the index cannot be read or written.
+//
+// Example Usage:
+// ```go
+// stats, err := UpdateFileIndex("")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Rescanned %d directories.\n", stats.DirectoriesScanned)
+//	}
+//
+// ```
+func UpdateFileIndex(indexPath string) (IndexUpdateStats, error) {
+	previous, err := LoadFileIndex(indexPath)
+	if err != nil {
+		return IndexUpdateStats{}, err
+	}
+
+	index := &FileIndex{Roots: previous.Roots, WithHashes: previous.WithHashes}
+	stats := index.refresh(previous)
+	return stats, index.Save(indexPath)
+}
+
+// LoadFileIndex reads an index from disk.
+//
+// Parameters:
+// - indexPath (string): The index file to read (use "" for DefaultIndexPath()).
+//
+// Returns:
+// - *FileIndex: The loaded index.
+// - error: An error if the index does not exist or cannot be decoded.
+func LoadFileIndex(indexPath string) (*FileIndex, error) {
+	if indexPath == "" {
+		indexPath = DefaultIndexPath()
+	}
+
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index %s (run 'index build' first): %v", indexPath, err)
+	}
+	defer file.Close()
+
+	var index FileIndex
+	if err := gob.NewDecoder(file).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %v", indexPath, err)
+	}
+	return &index, nil
+}
+
+// Save writes the index to disk, replacing the previous file atomically.
+//
+// Parameters:
+// - indexPath (string): Where to write the index (use "" for DefaultIndexPath()).
+//
+// Returns:
+// - error: An error if the index cannot be written.
+func (index *FileIndex) Save(indexPath string) error {
+	if indexPath == "" {
+		indexPath = DefaultIndexPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(indexPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create index directory: %v", err)
+	}
+
+	tempPath := indexPath + ".tmp"
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return fmt.Errorf("failed to create index %s: %v", indexPath, err)
+	}
+	if err := gob.NewEncoder(file).Encode(index); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write index %s: %v", indexPath, err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write index %s: %v", indexPath, err)
+	}
+	return os.Rename(tempPath, indexPath)
+}
+
+// Query returns the index entries matching the query, sorted by path.
+//
+// Parameters:
+// - query (IndexQuery): The name pattern, location, size and time filters.
+//
+// Returns:
+// - []IndexEntry: The matching entries.
+//
+// Example Usage:
+// ```go
+// index, _ := LoadFileIndex("")
+// entries := index.Query(IndexQuery{Pattern: "*.log", MinSize: 100 * 1024 * 1024})
+// fmt.Println("Large logs:", len(entries))
+// ```
+func (index *FileIndex) Query(query IndexQuery) []IndexEntry {
+	var under []string
+	for _, dir := range query.Under {
+		if absDir, err := filepath.Abs(dir); err == nil {
+			under = append(under, absDir)
+		}
+	}
+
+	var results []IndexEntry
+	for path, entry := range index.Entries {
+		if query.Pattern != "" {
+			if match, _ := filepath.Match(query.Pattern, filepath.Base(path)); !match {
+				continue
+			}
+		}
+		if len(under) > 0 && !isUnderAny(path, under) {
+			continue
+		}
+		if query.MinSize > 0 && entry.Size < query.MinSize {
+			continue
+		}
+		if query.MaxSize > 0 && entry.Size > query.MaxSize {
+			continue
+		}
+		if !query.ModifiedAfter.IsZero() && !entry.ModTime.After(query.ModifiedAfter) {
+			continue
+		}
+		if !query.ModifiedBefore.IsZero() && !entry.ModTime.Before(query.ModifiedBefore) {
+			continue
+		}
+		results = append(results, entry)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	return results
+}
+
+// FindFilesInIndex is the index-backed equivalent of FindFiles.
+//
+// Description:
+// - Answers the search from the index instead of walking the filesystem.
+// - Results are only as current as the last index build or update.
+//
+// Parameters:
+// - indexPath (string): The index to query (use "" for DefaultIndexPath()).
+// - filesToFind (string): The pattern of files to find (e.g., "*.txt").
+// - searchAllDrives (bool): Whether to search every indexed root or a specific directory.
+// - checkThisDisk (string): The specific directory to search (ignored if searchAllDrives is true).
+//
+// Returns:
+// - []string: A slice of matching file paths.
+// - error: An error if the index cannot be read.
+//
+// Example Usage:
+// ```go
+// files, err := FindFilesInIndex("", "*.txt", false, "/home")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Found files:", files)
+//	}
+//
+// ```
+func FindFilesInIndex(indexPath, filesToFind string, searchAllDrives bool, checkThisDisk string) ([]string, error) {
+	index, err := LoadFileIndex(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	query := IndexQuery{Pattern: filesToFind}
+	if !searchAllDrives {
+		query.Under = getSearchRoots(false, checkThisDisk)
+	}
+
+	var files []string
+	for _, entry := range index.Query(query) {
+		files = append(files, entry.Path)
+	}
+	return files, nil
+}
+
+// refresh rebuilds the index entries from disk, reusing listings from previous where possible.
+func (index *FileIndex) refresh(previous *FileIndex) IndexUpdateStats {
+	var stats IndexUpdateStats
+	index.Entries = map[string]IndexEntry{}
+	index.Directories = map[string]IndexedDirectory{}
+
+	for _, root := range index.Roots {
+		fmt.Printf("Indexing: %s\n", root)
+		info, err := os.Lstat(root)
+		if err != nil {
+			fmt.Printf("Failed to index %s: %v\n", root, err)
+			continue
+		}
+		index.addEntry(root, info, previous)
+		if info.IsDir() {
+			index.scanDirectory(root, info, previous, &stats)
+		}
+	}
+
+	index.UpdatedAt = time.Now()
+	stats.Entries = len(index.Entries)
+	return stats
+}
+
+// scanDirectory indexes a directory's children, recursing into subdirectories.
+// Every child it stats waits for the files-per-second limit; children reused from the previous index are not read.
+func (index *FileIndex) scanDirectory(dir string, info os.FileInfo, previous *FileIndex, stats *IndexUpdateStats) {
+	if runtime.GOOS != "windows" && virtualFilesystemPaths[dir] {
+		return
+	}
+
+	if previous != nil {
+		if cached, ok := previous.Directories[dir]; ok && cached.ModTime.Equal(info.ModTime()) {
+			stats.DirectoriesReused++
+			index.Directories[dir] = cached
+			for _, name := range cached.Children {
+				childPath := filepath.Join(dir, name)
+				entry, ok := previous.Entries[childPath]
+				if !ok {
+					continue
+				}
+				if !entry.Mode.IsDir() {
+					index.Entries[childPath] = entry
+					continue
+				}
+				// Subdirectories are checked individually since their changes do not touch this directory.
+				waitForFile()
+				childInfo, err := os.Lstat(childPath)
+				if err != nil {
+					continue
+				}
+				index.addEntry(childPath, childInfo, previous)
+				index.scanDirectory(childPath, childInfo, previous, stats)
+			}
+			return
+		}
+	}
+
+	stats.DirectoriesScanned++
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return // Skip unreadable directories
+	}
+
+	children := make([]string, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		childPath := filepath.Join(dir, dirEntry.Name())
+		waitForFile()
+		childInfo, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		children = append(children, dirEntry.Name())
+		index.addEntry(childPath, childInfo, previous)
+		if childInfo.IsDir() {
+			index.scanDirectory(childPath, childInfo, previous, stats)
+		}
+	}
+	index.Directories[dir] = IndexedDirectory{ModTime: info.ModTime(), Children: children}
+}
+
+// addEntry records a single path, reusing the previous hash if the file is unchanged.
+func (index *FileIndex) addEntry(path string, info os.FileInfo, previous *FileIndex) {
+	entry := IndexEntry{
+		Path:    path,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Mode:    info.Mode(),
+	}
+
+	if index.WithHashes && info.Mode().IsRegular() {
+		if previous != nil {
+			if old, ok := previous.Entries[path]; ok && old.Hash != "" && old.Size == entry.Size && old.ModTime.Equal(entry.ModTime) {
+				entry.Hash = old.Hash
+			}
+		}
+		if entry.Hash == "" {
+			entry.Hash, _ = GetFileHash(path, "sha256")
+		}
+	}
+
+	index.Entries[path] = entry
+}
+
+// isUnderAny reports whether path is one of the directories or below one of them.
+func isUnderAny(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if path == dir {
+			return true
+		}
+		prefix := dir
+		if !strings.HasSuffix(prefix, string(os.PathSeparator)) {
+			prefix += string(os.PathSeparator)
+		}
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
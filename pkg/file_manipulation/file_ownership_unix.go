@@ -0,0 +1,43 @@
+// This module is Unix-specific (Linux and macOS).
+
+//go:build !windows
+
+package file_manipulation
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwnership holds the ownership and inode details of a file where the platform exposes them.
+type fileOwnership struct {
+	UID   int
+	GID   int
+	Inode uint64
+	Links uint64
+	Known bool // False if the platform does not report ownership
+}
+
+// getFileOwnership reads the owner, group, inode and hard link count from a FileInfo.
+func getFileOwnership(info os.FileInfo) fileOwnership {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileOwnership{UID: -1, GID: -1}
+	}
+	return fileOwnership{
+		UID:   int(stat.Uid),
+		GID:   int(stat.Gid),
+		Inode: uint64(stat.Ino),
+		Links: uint64(stat.Nlink),
+		Known: true,
+	}
+}
+
+// getOpenFileLinks returns the hard link count of an open file.
+func getOpenFileLinks(file *os.File) (uint64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return getFileOwnership(info).Links, nil
+}
@@ -0,0 +1,36 @@
+// This module is Windows-specific.
+
+//go:build windows
+
+package file_manipulation
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// fileOwnership holds the ownership and inode details of a file where the platform exposes them.
+type fileOwnership struct {
+	UID   int
+	GID   int
+	Inode uint64
+	Links uint64
+	Known bool // False if the platform does not report ownership
+}
+
+// getFileOwnership reads the owner, group, inode and hard link count from a FileInfo.
+// Windows does not expose POSIX ownership through os.FileInfo, so nothing is known.
+func getFileOwnership(info os.FileInfo) fileOwnership {
+	return fileOwnership{UID: -1, GID: -1}
+}
+
+// getOpenFileLinks returns the hard link count of an open file.
+// os.FileInfo does not carry it on Windows, so it is read from the handle with GetFileInformationByHandle.
+func getOpenFileLinks(file *os.File) (uint64, error) {
+	var data windows.ByHandleFileInformation
+	if err := windows.GetFileInformationByHandle(windows.Handle(file.Fd()), &data); err != nil {
+		return 0, err
+	}
+	return uint64(data.NumberOfLinks), nil
+}
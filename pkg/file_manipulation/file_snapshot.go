@@ -0,0 +1,325 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// SnapshotEntry records the state of a single path in a Snapshot.
+type SnapshotEntry struct {
+	Path       string      `json:"path"` // Slash-separated path relative to the snapshot root
+	Size       int64       `json:"size"`
+	Mode       os.FileMode `json:"mode"`
+	ModTime    time.Time   `json:"mtime"`
+	UID        int         `json:"uid"` // -1 if not available on the platform
+	GID        int         `json:"gid"` // -1 if not available on the platform
+	Inode      uint64      `json:"inode,omitempty"`
+	MD5        string      `json:"md5,omitempty"`
+	SHA256     string      `json:"sha256,omitempty"`
+	LinkTarget string      `json:"link_target,omitempty"`
+}
+
+// Snapshot is a baseline of a directory tree used for file integrity monitoring.
+type Snapshot struct {
+	Hostname  string          `json:"hostname"`
+	Root      string          `json:"root"`
+	CreatedAt time.Time       `json:"created_at"`
+	Entries   []SnapshotEntry `json:"entries"`
+}
+
+// SnapshotChange describes one difference between two snapshots.
+type SnapshotChange struct {
+	Path    string   `json:"path"`
+	Change  string   `json:"change"`  // "added", "removed", "modified" or "permissions"
+	Details []string `json:"details"` // Human-readable before/after values
+}
+
+// CaptureSnapshot records the current state of a directory tree.
+//
+// Description:
+// - Walks the tree and records path, size, mode, owner, group, inode and modification time of every entry.
+// - Hashes regular files with MD5 and SHA-256 and records symbolic link targets without following them.
+// - Paths are stored relative to the root so snapshots taken on different hosts can be compared.
+//
+// Parameters:
+// - root (string): The directory to capture.
+//
+// Returns:
+// - Snapshot: The captured snapshot.
+// - error: An error if the root cannot be accessed.
+//
+// Example Usage:
+// ```go
+// snapshot, err := CaptureSnapshot("/etc")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Captured %d entries.\n", len(snapshot.Entries))
+//	}
+//
+// ```
+func CaptureSnapshot(root string) (Snapshot, error) {
+	root = filepath.Clean(root)
+	if _, err := os.Lstat(root); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to access %s: %v", root, err)
+	}
+
+	hostname, _ := os.Hostname()
+	fmt.Printf("Capturing snapshot of: %s\n", root)
+	readLink := func(name string) (string, error) {
+		return os.Readlink(filepath.Join(root, filepath.FromSlash(name)))
+	}
+	snapshot, err := captureSnapshot(os.DirFS(root), readLink)
+	snapshot.Hostname = hostname
+	snapshot.Root = root
+	if err != nil {
+		return snapshot, fmt.Errorf("error capturing snapshot of %s: %v", root, err)
+	}
+
+	return snapshot, nil
+}
+
+// CaptureSnapshotFS is CaptureSnapshot for any fs.FS, such as a mounted image or a MemFS.
+//
+// Description:
+// - Captures the whole of fsys; use fs.Sub to capture a subdirectory. Entry paths are fsys names.
+// - Hostname and Root are left empty, since fsys may come from another machine, so DiffSnapshots does not compare its inodes.
+// - Owners and inodes are recorded only where fsys returns the platform's file information (e.g., os.DirFS).
+// - Symbolic link targets are recorded only if fsys has a ReadLink(name string) (string, error) method.
+//
+// Parameters:
+// - fsys (fs.FS): The file system to capture.
+//
+// Returns:
+// - Snapshot: The captured snapshot.
+// - error: An error if the tree cannot be walked.
+//
+// Example Usage:
+// ```go
+// baseline, _ := LoadSnapshot("etc-baseline.json")
+// current, err := CaptureSnapshotFS(os.DirFS("/mnt/image/etc"))
+// changes := DiffSnapshots(baseline, current)
+// ```
+func CaptureSnapshotFS(fsys fs.FS) (Snapshot, error) {
+	var readLink func(name string) (string, error)
+	if linkFS, ok := fsys.(readLinkFS); ok {
+		readLink = linkFS.ReadLink
+	}
+	return captureSnapshot(fsys, readLink)
+}
+
+// readLinkFS is implemented by file systems that can read symbolic links, such as os.DirFS from Go 1.25 on.
+type readLinkFS interface {
+	ReadLink(name string) (string, error)
+}
+
+// captureSnapshot records every entry of fsys; readLink reads link targets and may be nil.
+func captureSnapshot(fsys fs.FS, readLink func(name string) (string, error)) (Snapshot, error) {
+	snapshot := Snapshot{CreatedAt: time.Now().UTC()}
+	err := walkFS(fsys, ".", nil, WalkOptions{}, func(name, _ string, info fs.FileInfo) error {
+		ownership := getFileOwnership(info)
+		entry := SnapshotEntry{
+			Path:    name,
+			Size:    info.Size(),
+			Mode:    info.Mode(),
+			ModTime: info.ModTime().UTC(),
+			UID:     ownership.UID,
+			GID:     ownership.GID,
+			Inode:   ownership.Inode,
+		}
+
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			if readLink != nil {
+				entry.LinkTarget, _ = readLink(name)
+			}
+		case info.Mode().IsRegular():
+			if sums, err := getFileHashes(fsys, name, "md5", "sha256"); err == nil {
+				entry.MD5 = sums["md5"]
+				entry.SHA256 = sums["sha256"]
+			}
+		}
+
+		snapshot.Entries = append(snapshot.Entries, entry)
+		return nil
+	})
+	return snapshot, err
+}
+
+// CreateSnapshot captures a directory tree and writes the snapshot to a JSON file.
+//
+// Parameters:
+// - root (string): The directory to capture.
+// - snapshotPath (string): The file to write the snapshot to.
+//
+// Returns:
+// - Snapshot: The captured snapshot.
+// - error: An error if the tree cannot be captured or the file cannot be written.
+//
+// Example Usage:
+// ```go
+// _, err := CreateSnapshot("/etc", "etc-baseline.json")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func CreateSnapshot(root, snapshotPath string) (Snapshot, error) {
+	snapshot, err := CaptureSnapshot(root)
+	if err != nil {
+		return snapshot, err
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	if err := os.WriteFile(snapshotPath, data, 0o600); err != nil {
+		return snapshot, fmt.Errorf("failed to write snapshot %s: %v", snapshotPath, err)
+	}
+
+	return snapshot, nil
+}
+
+// LoadSnapshot reads a snapshot written by CreateSnapshot.
+//
+// Parameters:
+// - snapshotPath (string): The snapshot file to read.
+//
+// Returns:
+// - Snapshot: The loaded snapshot.
+// - error: An error if the file cannot be read or parsed.
+func LoadSnapshot(snapshotPath string) (Snapshot, error) {
+	var snapshot Snapshot
+
+	data, err := os.ReadFile(snapshotPath)
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to read snapshot %s: %v", snapshotPath, err)
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to parse snapshot %s: %v", snapshotPath, err)
+	}
+
+	return snapshot, nil
+}
+
+// DiffSnapshots compares two snapshots and reports what changed between them.
+//
+// Description:
+// - Reports added and removed entries.
+// - Reports entries whose type, size, hash or link target changed as "modified".
+// - Reports entries whose permission bits, owner or group changed as "permissions".
+// - Inode changes with identical content are reported as modified only when both snapshots come from the same host.
+// - Modification times are not compared on their own, since they are easy to forge.
+//
+// Parameters:
+// - baseline (Snapshot): The earlier snapshot.
+// - current (Snapshot): The later snapshot.
+//
+// Returns:
+// - []SnapshotChange: The changes, sorted by path.
+//
+// Example Usage:
+// ```go
+// baseline, _ := LoadSnapshot("etc-baseline.json")
+// current, _ := CaptureSnapshot("/etc")
+//
+//	for _, change := range DiffSnapshots(baseline, current) {
+//	    fmt.Println(change.Change, change.Path, change.Details)
+//	}
+//
+// ```
+func DiffSnapshots(baseline, current Snapshot) []SnapshotChange {
+	sameHost := baseline.Hostname != "" && baseline.Hostname == current.Hostname
+
+	before := make(map[string]SnapshotEntry, len(baseline.Entries))
+	for _, entry := range baseline.Entries {
+		before[entry.Path] = entry
+	}
+	after := make(map[string]SnapshotEntry, len(current.Entries))
+	for _, entry := range current.Entries {
+		after[entry.Path] = entry
+	}
+
+	var changes []SnapshotChange
+	for path, old := range before {
+		entry, ok := after[path]
+		if !ok {
+			changes = append(changes, SnapshotChange{Path: path, Change: "removed", Details: []string{describeSnapshotEntry(old)}})
+			continue
+		}
+
+		var modified []string
+		if old.Mode.Type() != entry.Mode.Type() {
+			modified = append(modified, fmt.Sprintf("type %s -> %s", old.Mode.Type(), entry.Mode.Type()))
+		}
+		if old.Size != entry.Size && !entry.Mode.IsDir() {
+			modified = append(modified, fmt.Sprintf("size %d -> %d", old.Size, entry.Size))
+		}
+		if old.SHA256 != entry.SHA256 {
+			modified = append(modified, fmt.Sprintf("sha256 %s -> %s", old.SHA256, entry.SHA256))
+		}
+		if old.LinkTarget != entry.LinkTarget {
+			modified = append(modified, fmt.Sprintf("link target %s -> %s", old.LinkTarget, entry.LinkTarget))
+		}
+		if sameHost && old.Inode != entry.Inode {
+			modified = append(modified, fmt.Sprintf("inode %d -> %d", old.Inode, entry.Inode))
+		}
+		if len(modified) > 0 {
+			if !old.ModTime.Equal(entry.ModTime) {
+				modified = append(modified, fmt.Sprintf("mtime %s -> %s", old.ModTime.Format(time.RFC3339), entry.ModTime.Format(time.RFC3339)))
+			}
+			changes = append(changes, SnapshotChange{Path: path, Change: "modified", Details: modified})
+		}
+
+		var permissions []string
+		if old.Mode.Perm() != entry.Mode.Perm() || old.Mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) != entry.Mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) {
+			permissions = append(permissions, fmt.Sprintf("mode %s -> %s", old.Mode, entry.Mode))
+		}
+		if old.UID != entry.UID {
+			permissions = append(permissions, fmt.Sprintf("uid %d -> %d", old.UID, entry.UID))
+		}
+		if old.GID != entry.GID {
+			permissions = append(permissions, fmt.Sprintf("gid %d -> %d", old.GID, entry.GID))
+		}
+		if len(permissions) > 0 {
+			changes = append(changes, SnapshotChange{Path: path, Change: "permissions", Details: permissions})
+		}
+	}
+
+	for path, entry := range after {
+		if _, ok := before[path]; !ok {
+			changes = append(changes, SnapshotChange{Path: path, Change: "added", Details: []string{describeSnapshotEntry(entry)}})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Change < changes[j].Change
+	})
+	return changes
+}
+
+// describeSnapshotEntry summarizes an entry for added and removed changes.
+func describeSnapshotEntry(entry SnapshotEntry) string {
+	description := fmt.Sprintf("%s uid=%d gid=%d size=%d", entry.Mode, entry.UID, entry.GID, entry.Size)
+	if entry.SHA256 != "" {
+		description += " sha256=" + entry.SHA256
+	}
+	if entry.LinkTarget != "" {
+		description += " -> " + entry.LinkTarget
+	}
+	return description
+}
@@ -0,0 +1,49 @@
+// This module is Linux-specific.
+
+//go:build linux
+
+package file_manipulation
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileTimes holds the MACB timestamps of a file. Times the platform does not report are zero.
+type fileTimes struct {
+	Access time.Time
+	Modify time.Time
+	Change time.Time // Metadata change (inode change) time
+	Birth  time.Time // Creation time
+}
+
+// getFileTimes reads the access, modification, change and birth times of a file without
+// following symbolic links. Birth time comes from statx and is zero if the kernel or
+// filesystem does not report it.
+func getFileTimes(path string, info os.FileInfo) fileTimes {
+	var stat unix.Statx_t
+	err := unix.Statx(unix.AT_FDCWD, path, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BASIC_STATS|unix.STATX_BTIME, &stat)
+	if err != nil {
+		var fallback unix.Stat_t
+		if err := unix.Lstat(path, &fallback); err != nil {
+			return fileTimes{Modify: info.ModTime()}
+		}
+		return fileTimes{
+			Access: time.Unix(fallback.Atim.Unix()),
+			Modify: time.Unix(fallback.Mtim.Unix()),
+			Change: time.Unix(fallback.Ctim.Unix()),
+		}
+	}
+
+	times := fileTimes{
+		Access: time.Unix(stat.Atime.Sec, int64(stat.Atime.Nsec)),
+		Modify: time.Unix(stat.Mtime.Sec, int64(stat.Mtime.Nsec)),
+		Change: time.Unix(stat.Ctime.Sec, int64(stat.Ctime.Nsec)),
+	}
+	if stat.Mask&unix.STATX_BTIME != 0 {
+		times.Birth = time.Unix(stat.Btime.Sec, int64(stat.Btime.Nsec))
+	}
+	return times
+}
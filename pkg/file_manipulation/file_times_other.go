@@ -0,0 +1,34 @@
+// This module is for Unix platforms other than Linux (e.g., macOS).
+
+//go:build !linux && !windows
+
+package file_manipulation
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// fileTimes holds the MACB timestamps of a file. Times the platform does not report are zero.
+type fileTimes struct {
+	Access time.Time
+	Modify time.Time
+	Change time.Time // Metadata change (inode change) time
+	Birth  time.Time // Creation time
+}
+
+// getFileTimes reads the access, modification and change times of a file without following
+// symbolic links. Birth time is only read on Linux and Windows, so Birth is zero.
+func getFileTimes(path string, info os.FileInfo) fileTimes {
+	var stat unix.Stat_t
+	if err := unix.Lstat(path, &stat); err != nil {
+		return fileTimes{Modify: info.ModTime()}
+	}
+	return fileTimes{
+		Access: time.Unix(stat.Atim.Unix()),
+		Modify: time.Unix(stat.Mtim.Unix()),
+		Change: time.Unix(stat.Ctim.Unix()),
+	}
+}
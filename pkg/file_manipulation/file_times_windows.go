@@ -0,0 +1,33 @@
+// This module is Windows-specific.
+
+//go:build windows
+
+package file_manipulation
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileTimes holds the MACB timestamps of a file. Times the platform does not report are zero.
+type fileTimes struct {
+	Access time.Time
+	Modify time.Time
+	Change time.Time // Metadata change (inode change) time
+	Birth  time.Time // Creation time
+}
+
+// getFileTimes reads the access, modification and creation times of a file.
+// Windows does not expose the metadata change time through os.FileInfo, so Change is zero.
+func getFileTimes(path string, info os.FileInfo) fileTimes {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return fileTimes{Modify: info.ModTime()}
+	}
+	return fileTimes{
+		Access: time.Unix(0, data.LastAccessTime.Nanoseconds()),
+		Modify: time.Unix(0, data.LastWriteTime.Nanoseconds()),
+		Birth:  time.Unix(0, data.CreationTime.Nanoseconds()),
+	}
+}
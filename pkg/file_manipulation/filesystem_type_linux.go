@@ -0,0 +1,31 @@
+// This module is Linux-specific.
+
+//go:build linux
+
+package file_manipulation
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// zfsSuperMagic is the statfs type reported by ZFS on Linux (not defined in x/sys/unix).
+const zfsSuperMagic = 0x2fc12fc1
+
+// copyOnWriteFilesystem returns the name of the copy-on-write filesystem holding path,
+// or "" if the filesystem overwrites file data in place.
+func copyOnWriteFilesystem(path string) string {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return ""
+	}
+
+	switch uint32(stat.Type) {
+	case uint32(unix.BTRFS_SUPER_MAGIC):
+		return "btrfs"
+	case uint32(unix.BCACHEFS_SUPER_MAGIC):
+		return "bcachefs"
+	case zfsSuperMagic:
+		return "zfs"
+	}
+	return ""
+}
@@ -0,0 +1,11 @@
+// This module is for platforms other than Linux (Windows and macOS).
+
+//go:build !linux
+
+package file_manipulation
+
+// copyOnWriteFilesystem returns the name of the copy-on-write filesystem holding path,
+// or "" if the filesystem overwrites file data in place. Detection is only implemented on Linux.
+func copyOnWriteFilesystem(path string) string {
+	return ""
+}
@@ -0,0 +1,100 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// BrokenSymlink is a symbolic link that cannot be safely resolved.
+type BrokenSymlink struct {
+	Path   string
+	Target string
+	Reason string // "dangling", "loop" or "ancestor-loop"
+}
+
+// FindBrokenSymlinks finds dangling and looping symbolic links, optionally removing them.
+//
+// Description:
+// - Walks the tree without following symbolic links.
+// - Reports links whose target does not exist ("dangling") and links that resolve through themselves ("loop").
+// - Reports links that point to one of their own parent directories ("ancestor-loop").
+// - Ancestor loops resolve, but following them recurses forever, so they are reported and never removed.
+// - There is no FS variant, since io/fs has no way to read or resolve symbolic links before Go 1.25.
+//
+// Parameters:
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+// - remove (bool): Whether to remove dangling and looping links.
+//
+// Returns:
+// - []BrokenSymlink: The broken links found; with remove, links that could not be removed are left out.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// links, err := FindBrokenSymlinks(false, "/opt", false)
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, link := range links {
+//	        fmt.Println(link.Reason, link.Path, "->", link.Target)
+//	    }
+//	}
+//
+// ```
+func FindBrokenSymlinks(searchAllDrives bool, checkThisDisk string, remove bool) ([]BrokenSymlink, error) {
+	var brokenLinks []BrokenSymlink
+
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Searching for broken symbolic links in: %s\n", drive)
+		err := WalkFiles(drive, WalkOptions{}, func(path string, info os.FileInfo) error {
+			if info.IsDir() && runtime.GOOS != "windows" && virtualFilesystemPaths[path] {
+				return filepath.SkipDir
+			}
+			if info.Mode()&os.ModeSymlink == 0 {
+				return nil
+			}
+
+			target, _ := os.Readlink(path)
+			link := BrokenSymlink{Path: path, Target: target}
+
+			resolved, err := filepath.EvalSymlinks(path)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				link.Reason = "dangling"
+			case errors.Is(err, syscall.ELOOP) || (err != nil && strings.Contains(err.Error(), "too many links")):
+				link.Reason = "loop"
+			case err != nil:
+				return nil // Unreadable targets (e.g., permission denied) are not reported
+			case isUnderAny(filepath.Dir(path), []string{resolved}):
+				link.Reason = "ancestor-loop"
+			default:
+				return nil
+			}
+
+			if remove && link.Reason != "ancestor-loop" {
+				if err := os.Remove(path); err != nil {
+					fmt.Printf("Failed to remove link: %s. Error: %v\n", path, err)
+					return nil
+				}
+			}
+			brokenLinks = append(brokenLinks, link)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+	}
+
+	return brokenLinks, nil
+}
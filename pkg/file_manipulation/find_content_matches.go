@@ -0,0 +1,338 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ContentMatch is one occurrence of a search string in a file.
+type ContentMatch struct {
+	Path       string
+	Encoding   string // Encoding the match was found in (e.g., "UTF-16LE")
+	Line       int    // 1-based line number in the decoded text (0 for raw byte matches)
+	ByteOffset int64  // Offset of the match in the file, counting any byte order mark
+	CharOffset int64  // Offset in characters from the start of the decoded text (-1 for raw byte matches)
+}
+
+// FindContentMatches finds every occurrence of a string in files, decoding each file first.
+//
+// Description:
+// - Detects each file's encoding (UTF-8, UTF-16LE/BE with or without a BOM, or Latin-1) and decodes it before matching.
+// - Reports each match with its line, its byte offset in the file and its character offset in the decoded text.
+// - Searches files of a specific type and size, in all drives or a specific directory.
+//
+// Parameters:
+// - stringToFind (string): The string to search for within files.
+// - fileTypeToSearch (string): The file extension to filter by (e.g., ".log").
+// - maxFileSizeKB (int): The maximum file size in kilobytes to search.
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+//
+// Returns:
+// - []ContentMatch: The matches, by file and offset.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// matches, err := FindContentMatches("Installation failed", ".log", 4096, false, "C:\\Windows\\Logs")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, match := range matches {
+//	        fmt.Printf("%s:%d (%s, byte %d, char %d)\n", match.Path, match.Line, match.Encoding, match.ByteOffset, match.CharOffset)
+//	    }
+//	}
+//
+// ```
+func FindContentMatches(stringToFind, fileTypeToSearch string, maxFileSizeKB int, searchAllDrives bool, checkThisDisk string) ([]ContentMatch, error) {
+	if stringToFind == "" {
+		return nil, fmt.Errorf("search string cannot be empty")
+	}
+
+	var matches []ContentMatch
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Searching for content in: %s\n", drive)
+		driveMatches, err := findContentMatches(os.DirFS(drive), osPathFunc(drive), stringToFind, fileTypeToSearch, maxFileSizeKB)
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+		matches = append(matches, driveMatches...)
+	}
+
+	return matches, nil
+}
+
+// FindContentMatchesFS is FindContentMatches for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; use fs.Sub to search a subdirectory.
+// - Match paths are fsys names (slash-separated, e.g., "logs/setup.log").
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - stringToFind (string): The string to search for within files.
+// - fileTypeToSearch (string): The file extension to filter by (e.g., ".log").
+// - maxFileSizeKB (int): The maximum file size in kilobytes to search.
+//
+// Returns:
+// - []ContentMatch: The matches, by file and offset.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// data, _ := EncodeString("Installation failed\n", EncodingUTF16LE)
+// fsys.WriteFile("logs/setup.log", data, 0o644)
+// matches, err := FindContentMatchesFS(fsys, "Installation failed", ".log", 4096)
+// ```
+func FindContentMatchesFS(fsys fs.FS, stringToFind, fileTypeToSearch string, maxFileSizeKB int) ([]ContentMatch, error) {
+	if stringToFind == "" {
+		return nil, fmt.Errorf("search string cannot be empty")
+	}
+	return findContentMatches(fsys, nil, stringToFind, fileTypeToSearch, maxFileSizeKB)
+}
+
+// findContentMatches searches fsys for every occurrence of a string, reporting paths converted with toPath.
+func findContentMatches(fsys fs.FS, toPath func(name string) string, stringToFind, fileTypeToSearch string, maxFileSizeKB int) ([]ContentMatch, error) {
+	var matches []ContentMatch
+	err := walkFS(fsys, ".", toPath, WalkOptions{}, func(name, path string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() || filepath.Ext(name) != fileTypeToSearch || info.Size() > int64(maxFileSizeKB)*1024 {
+			return nil
+		}
+		fileMatches, err := findTextMatches(fsys, name, path, stringToFind)
+		if err == nil {
+			matches = append(matches, fileMatches...)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// FindEncodedStringInFiles searches the raw bytes of files for a string in several encodings at once.
+//
+// Description:
+// - Encodes the string in each encoding and scans every matching file, including binary files such as memory dumps or executables.
+// - Encodings that produce the same bytes (e.g., UTF-8 and Latin-1 for ASCII text) are reported together.
+// - Files are read in chunks, so there is no size limit.
+// - An ASCII string in UTF-16LE also matches as UTF-16BE one byte later (and the reverse), so both may be reported for the same text.
+//
+// Parameters:
+// - stringToFind (string): The string to search for.
+// - encodings ([]string): The encodings to search in (default: UTF-8, UTF-16LE and UTF-16BE).
+// - filesToFind (string): The pattern of files to search (e.g., "*").
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+//
+// Returns:
+// - []ContentMatch: The matches with their byte offsets; Line is 0 and CharOffset is -1.
+// - error: An error if an encoding is not supported or the search fails.
+//
+// Example Usage:
+// ```go
+// matches, err := FindEncodedStringInFiles("evil.example.com", nil, "*", false, "/tmp/dumps")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, match := range matches {
+//	        fmt.Printf("%s @%d (%s)\n", match.Path, match.ByteOffset, match.Encoding)
+//	    }
+//	}
+//
+// ```
+func FindEncodedStringInFiles(stringToFind string, encodings []string, filesToFind string, searchAllDrives bool, checkThisDisk string) ([]ContentMatch, error) {
+	needles, err := newEncodedNeedles(stringToFind, encodings)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []ContentMatch
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Searching for encoded content in: %s\n", drive)
+		driveMatches, err := findEncodedString(os.DirFS(drive), osPathFunc(drive), needles, filesToFind)
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+		matches = append(matches, driveMatches...)
+	}
+
+	return matches, nil
+}
+
+// FindEncodedStringInFilesFS is FindEncodedStringInFiles for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; use fs.Sub to search a subdirectory.
+// - Match paths are fsys names (slash-separated, e.g., "dumps/lsass.dmp").
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - stringToFind (string): The string to search for.
+// - encodings ([]string): The encodings to search in (nil for UTF-8, UTF-16LE and UTF-16BE).
+// - filesToFind (string): The pattern of files to search (e.g., "*").
+//
+// Returns:
+// - []ContentMatch: The matches, with their byte offsets and encodings.
+// - error: An error if an encoding is not supported or the operation fails.
+//
+// Example Usage:
+// ```go
+// matches, err := FindEncodedStringInFilesFS(os.DirFS("/mnt/evidence"), "evil.example.com", nil, "*.dmp")
+// ```
+func FindEncodedStringInFilesFS(fsys fs.FS, stringToFind string, encodings []string, filesToFind string) ([]ContentMatch, error) {
+	needles, err := newEncodedNeedles(stringToFind, encodings)
+	if err != nil {
+		return nil, err
+	}
+	return findEncodedString(fsys, nil, needles, filesToFind)
+}
+
+// encodedNeedle is the search string encoded in one or more encodings that give the same bytes.
+type encodedNeedle struct {
+	data     []byte
+	encoding string
+}
+
+// newEncodedNeedles encodes the search string in each encoding, merging encodings that produce the same bytes.
+func newEncodedNeedles(stringToFind string, encodings []string) ([]encodedNeedle, error) {
+	if stringToFind == "" {
+		return nil, fmt.Errorf("search string cannot be empty")
+	}
+	if len(encodings) == 0 {
+		encodings = []string{EncodingUTF8, EncodingUTF16LE, EncodingUTF16BE}
+	}
+
+	var needles []encodedNeedle
+	for _, encoding := range encodings {
+		if canonicalEncoding(encoding) == "" {
+			return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+		}
+		data, err := EncodeString(stringToFind, encoding)
+		if err != nil {
+			return nil, err
+		}
+		merged := false
+		for i := range needles {
+			if bytes.Equal(needles[i].data, data) {
+				needles[i].encoding += ", " + canonicalEncoding(encoding)
+				merged = true
+			}
+		}
+		if !merged {
+			needles = append(needles, encodedNeedle{data: data, encoding: canonicalEncoding(encoding)})
+		}
+	}
+	return needles, nil
+}
+
+// findEncodedString searches the files in fsys matching filesToFind for any of the needles.
+func findEncodedString(fsys fs.FS, toPath func(name string) string, needles []encodedNeedle, filesToFind string) ([]ContentMatch, error) {
+	longest := 0
+	for _, needle := range needles {
+		longest = max(longest, len(needle.data))
+	}
+
+	var matches []ContentMatch
+	err := walkFS(fsys, ".", toPath, WalkOptions{Include: []string{filesToFind}}, func(name, path string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := fsys.Open(name)
+		if err != nil {
+			return nil
+		}
+		defer file.Close()
+
+		// Carry the last longest-1 bytes into the next chunk so matches spanning chunks are found.
+		reader := throttleReader(file)
+		chunk := make([]byte, 1024*1024)
+		var buffer []byte
+		var base int64
+		for {
+			carried := len(buffer)
+			n, readErr := reader.Read(chunk)
+			buffer = append(buffer, chunk[:n]...)
+			for _, needle := range needles {
+				for start := 0; ; {
+					index := bytes.Index(buffer[start:], needle.data)
+					if index < 0 {
+						break
+					}
+					offset := start + index
+					// Matches that end inside the carried bytes were reported with the previous chunk.
+					if offset+len(needle.data) > carried {
+						matches = append(matches, ContentMatch{Path: path, Encoding: needle.encoding, ByteOffset: base + int64(offset), CharOffset: -1})
+					}
+					start = offset + 1
+				}
+			}
+			if readErr != nil {
+				break
+			}
+			keep := min(longest-1, len(buffer))
+			base += int64(len(buffer) - keep)
+			buffer = append(buffer[:0], buffer[len(buffer)-keep:]...)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// readDecodedFile reads a file, detects its encoding and decodes it.
+// The returned offsets are byte offsets in the file (after the BOM) of each character of the text.
+func readDecodedFile(fsys fs.FS, name string) (string, []int, string, int, error) {
+	data, err := readFileFS(fsys, name)
+	if err != nil {
+		return "", nil, "", 0, err
+	}
+	encoding, bomLength := DetectTextEncoding(data)
+	text, offsets, err := DecodeText(data[bomLength:], encoding)
+	return text, offsets, encoding, bomLength, err
+}
+
+// findTextMatches decodes a file and returns every occurrence of a string with its line and offsets, reported under path.
+func findTextMatches(fsys fs.FS, name, path, stringToFind string) ([]ContentMatch, error) {
+	text, offsets, encoding, bomLength, err := readDecodedFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []ContentMatch
+	line, charOffset, scanned := 1, 0, 0
+	for start := 0; ; {
+		index := strings.Index(text[start:], stringToFind)
+		if index < 0 {
+			break
+		}
+		position := start + index
+		line += strings.Count(text[scanned:position], "\n")
+		charOffset += utf8.RuneCountInString(text[scanned:position])
+		scanned = position
+
+		matches = append(matches, ContentMatch{
+			Path:       path,
+			Encoding:   encoding,
+			Line:       line,
+			ByteOffset: int64(bomLength + offsets[charOffset]),
+			CharOffset: int64(charOffset),
+		})
+		start = position + max(len(stringToFind), 1)
+	}
+	return matches, nil
+}
+
+// fileContainsText reports whether the decoded contents of a file contain a string.
+func fileContainsText(fsys fs.FS, name, stringToFind string) bool {
+	text, _, _, _, err := readDecodedFile(fsys, name)
+	return err == nil && strings.Contains(text, stringToFind)
+}
@@ -0,0 +1,136 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+)
+
+// FindEmptyDirectories finds directories that contain no files, optionally removing them.
+//
+// Description:
+// - Scans each directory bottom-up; a directory counts as empty if it only contains empty directories.
+// - Symbolic links are never followed, and a directory containing a symlink is not empty.
+// - When remove is true, empty directories are removed deepest first, so parents emptied by their children's removal go in the same pass.
+// - The search root itself is never removed.
+//
+// Parameters:
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+// - remove (bool): Whether to remove the empty directories.
+//
+// Returns:
+// - []string: The empty directories, deepest first.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// dirs, err := FindEmptyDirectories(false, "/srv/exports", true)
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Removed %d empty directories.\n", len(dirs))
+//	}
+//
+// ```
+func FindEmptyDirectories(searchAllDrives bool, checkThisDisk string, remove bool) ([]string, error) {
+	var emptyDirs []string
+
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Searching for empty directories in: %s\n", drive)
+		if _, err := os.Lstat(drive); err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+		driveDirs, err := findEmptyDirectories(NewOSFS(drive), osPathFunc(drive), remove)
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+		emptyDirs = append(emptyDirs, driveDirs...)
+	}
+
+	return emptyDirs, nil
+}
+
+// FindEmptyDirectoriesFS is FindEmptyDirectories for a WritableFS, such as an OSFS or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; the root of fsys is never removed.
+// - Returns fsys names (slash-separated, e.g., "exports/2023").
+//
+// Parameters:
+// - fsys (WritableFS): The file system to search.
+// - remove (bool): Whether to remove the empty directories.
+//
+// Returns:
+// - []string: The empty directories, deepest first.
+// - error: An error if the root of fsys cannot be read.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// fsys.MkdirAll("exports/2023/q1", 0o755)
+// fsys.WriteFile("exports/2024/report.csv", nil, 0o644)
+// dirs, err := FindEmptyDirectoriesFS(fsys, true) // "exports/2023/q1", then "exports/2023"
+// ```
+func FindEmptyDirectoriesFS(fsys WritableFS, remove bool) ([]string, error) {
+	return findEmptyDirectories(fsys, nil, remove)
+}
+
+// findEmptyDirectories finds the empty directories below the root of fsys and returns their paths, converted with toPath.
+func findEmptyDirectories(fsys WritableFS, toPath func(name string) string, remove bool) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	if toPath == nil {
+		toPath = func(name string) string { return name }
+	}
+
+	var emptyDirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			collectEmptyDirectories(fsys, entry.Name(), toPath, remove, &emptyDirs)
+		}
+	}
+	return emptyDirs, nil
+}
+
+// collectEmptyDirectories appends dir and its empty subdirectories (deepest first) to emptyDirs
+// and reports whether dir itself is empty.
+func collectEmptyDirectories(fsys WritableFS, dir string, toPath func(name string) string, remove bool, emptyDirs *[]string) bool {
+	dirPath := toPath(dir)
+	if runtime.GOOS != "windows" && virtualFilesystemPaths[dirPath] {
+		return false
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return false // Unreadable directories are treated as not empty
+	}
+
+	empty := true
+	for _, entry := range entries {
+		// DirEntry.IsDir is false for symbolic links, so links to directories are never followed.
+		if !entry.IsDir() || !collectEmptyDirectories(fsys, path.Join(dir, entry.Name()), toPath, remove, emptyDirs) {
+			empty = false
+		}
+	}
+	if !empty {
+		return false
+	}
+
+	if remove {
+		if err := fsys.Remove(dir); err != nil {
+			fmt.Printf("Failed to remove directory: %s. Error: %v\n", dirPath, err)
+			return false
+		}
+	}
+	*emptyDirs = append(*emptyDirs, dirPath)
+	return true
+}
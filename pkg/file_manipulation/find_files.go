@@ -7,8 +7,6 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-
-	"github.com/Protheophage/GO/pkg/random_utilities"
 )
 
 // FindFiles searches for files based on a pattern.
@@ -41,46 +39,51 @@ import (
 func FindFiles(filesToFind string, searchAllDrives bool, checkThisDisk string) ([]string, error) {
 	var files []string
 
-	if searchAllDrives {
-		// Get all drives on the system (Windows-specific logic)
-		drives := random_utilities.GetAllDrives()
-		for _, drive := range drives {
-			fmt.Printf("Searching: %s for %s\n", drive, filesToFind)
-			err := filepath.Walk(drive, func(path string, info fs.FileInfo, err error) error {
-				if err != nil {
-					return nil // Skip errors
-				}
-				if match, _ := filepath.Match(filesToFind, info.Name()); match {
-					files = append(files, path)
-				}
-				return nil
-			})
-			if err != nil {
-				fmt.Printf("Error searching drive %s: %v\n", drive, err)
-			}
-		}
-	} else {
-		if checkThisDisk == "" {
-			if os.PathSeparator == '/' {
-				checkThisDisk = "/"
-			} else {
-				checkThisDisk = filepath.Join(os.Getenv("SystemDrive"), "")
-			}
-		}
-		fmt.Printf("Searching: %s for %s\n", checkThisDisk, filesToFind)
-		err := filepath.Walk(checkThisDisk, func(path string, info fs.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip errors
-			}
-			if match, _ := filepath.Match(filesToFind, info.Name()); match {
-				files = append(files, path)
-			}
-			return nil
-		})
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Searching: %s for %s\n", drive, filesToFind)
+		found, err := findFiles(os.DirFS(drive), osPathFunc(drive), filesToFind)
 		if err != nil {
-			return nil, fmt.Errorf("error searching directory %s: %v", checkThisDisk, err)
+			if !searchAllDrives {
+				return nil, fmt.Errorf("error searching directory %s: %v", drive, err)
+			}
+			fmt.Printf("Error searching drive %s: %v\n", drive, err)
 		}
+		files = append(files, found...)
 	}
 
 	return files, nil
 }
+
+// FindFilesFS is FindFiles for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; use fs.Sub to search a subdirectory.
+// - Returns fsys names (slash-separated, e.g., "logs/app.log").
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - filesToFind (string): The pattern of files to find (e.g., "*.txt").
+//
+// Returns:
+// - []string: The names of the matching files.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// files, err := FindFilesFS(os.DirFS("/mnt/evidence"), "*.evtx")
+// ```
+func FindFilesFS(fsys fs.FS, filesToFind string) ([]string, error) {
+	return findFiles(fsys, nil, filesToFind)
+}
+
+// findFiles returns the paths, converted with toPath, of the entries in fsys whose name matches a pattern.
+func findFiles(fsys fs.FS, toPath func(name string) string, filesToFind string) ([]string, error) {
+	var files []string
+	err := walkFS(fsys, ".", toPath, WalkOptions{}, func(_, path string, info fs.FileInfo) error {
+		if match, _ := filepath.Match(filesToFind, info.Name()); match {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
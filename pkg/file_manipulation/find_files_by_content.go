@@ -3,13 +3,10 @@
 package file_manipulation
 
 import (
-	"bufio"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
-
-	"github.com/Protheophage/GO/pkg/random_utilities"
 )
 
 // FindFilesByContent searches for files containing specific content.
@@ -17,6 +14,7 @@ import (
 // Description:
 // - Searches for files of a specific type and size containing a given string.
 // - Can search all drives or a specific directory.
+// - Detects each file's encoding (UTF-8, UTF-16LE/BE or Latin-1) and decodes it before matching.
 //
 // Parameters:
 // - stringToFind (string): The string to search for within files.
@@ -42,49 +40,55 @@ import (
 //
 // ```
 func FindFilesByContent(stringToFind, fileTypeToSearch string, maxFileSizeKB int, searchAllDrives bool, checkThisDisk string) ([]string, error) {
-	if checkThisDisk == "" {
-		if os.PathSeparator == '/' {
-			checkThisDisk = "/"
-		} else {
-			checkThisDisk = filepath.Join(os.Getenv("SystemDrive"), "")
-		}
-	}
-
-	var drives []string
-	if searchAllDrives {
-		drives = random_utilities.GetAllDrives()
-	} else {
-		drives = []string{checkThisDisk}
-	}
-
 	var foundFiles []string
-	for _, drive := range drives {
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
 		fmt.Printf("Searching for content in: %s\n", drive)
-		err := filepath.Walk(drive, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip errors
-			}
-			if filepath.Ext(path) == fileTypeToSearch && info.Size() <= int64(maxFileSizeKB*1024) {
-				file, err := os.Open(path)
-				if err != nil {
-					return nil
-				}
-				defer file.Close()
-
-				scanner := bufio.NewScanner(file)
-				for scanner.Scan() {
-					if strings.Contains(scanner.Text(), stringToFind) {
-						foundFiles = append(foundFiles, path)
-						break
-					}
-				}
-			}
-			return nil
-		})
+		files, err := findFilesByContent(os.DirFS(drive), osPathFunc(drive), stringToFind, fileTypeToSearch, maxFileSizeKB)
 		if err != nil {
 			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
 		}
+		foundFiles = append(foundFiles, files...)
 	}
 
 	return foundFiles, nil
 }
+
+// FindFilesByContentFS is FindFilesByContent for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; use fs.Sub to search a subdirectory.
+// - Returns fsys names (slash-separated, e.g., "etc/app.conf").
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - stringToFind (string): The string to search for within files.
+// - fileTypeToSearch (string): The file extension to filter by (e.g., ".txt").
+// - maxFileSizeKB (int): The maximum file size in kilobytes to search.
+//
+// Returns:
+// - []string: The names of the files containing the specified content.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// fsys.WriteFile("etc/app.conf", []byte("password=hunter2\n"), 0o600)
+// files, err := FindFilesByContentFS(fsys, "password", ".conf", 1024)
+// ```
+func FindFilesByContentFS(fsys fs.FS, stringToFind, fileTypeToSearch string, maxFileSizeKB int) ([]string, error) {
+	return findFilesByContent(fsys, nil, stringToFind, fileTypeToSearch, maxFileSizeKB)
+}
+
+// findFilesByContent searches fsys for files containing a string and returns their paths, converted with toPath.
+func findFilesByContent(fsys fs.FS, toPath func(name string) string, stringToFind, fileTypeToSearch string, maxFileSizeKB int) ([]string, error) {
+	var foundFiles []string
+	err := walkFS(fsys, ".", toPath, WalkOptions{}, func(name, path string, info fs.FileInfo) error {
+		if filepath.Ext(name) == fileTypeToSearch && info.Size() <= int64(maxFileSizeKB*1024) {
+			if fileContainsText(fsys, name, stringToFind) {
+				foundFiles = append(foundFiles, path)
+			}
+		}
+		return nil
+	})
+	return foundFiles, err
+}
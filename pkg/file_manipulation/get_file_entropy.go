@@ -0,0 +1,260 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+)
+
+// EntropyOptions controls how much of a file GetFileEntropy reads. Zero values use the defaults.
+type EntropyOptions struct {
+	SampleBlocks int   // Number of evenly spaced blocks to read (default: 0, the whole file)
+	BlockSize    int   // Size of each sampled block in bytes (default: 64 KB)
+	MinSize      int64 // Skip files smaller than this in FindHighEntropyFiles (default: 1 KB)
+}
+
+// EntropyResult is the entropy of a single file.
+type EntropyResult struct {
+	Path         string  `json:"path"`
+	Entropy      float64 `json:"entropy"` // Shannon entropy in bits per byte (0 to 8)
+	Size         int64   `json:"size"`
+	DetectedType string  `json:"detected_type"`
+}
+
+// GetFileEntropy computes the Shannon entropy of a file's contents in bits per byte.
+//
+// Description:
+// - Counts the byte distribution of the file and returns a value from 0 (one repeated byte) to 8 (uniformly random).
+// - Encrypted and packed data is close to 8; text is usually below 5.
+// - With options.SampleBlocks set, only that many evenly spaced blocks are read, including the first and last block.
+//
+// Parameters:
+// - path (string): The file to analyze.
+// - options (EntropyOptions): Whole-file or sampled reading.
+//
+// Returns:
+// - float64: The entropy in bits per byte (0 for an empty file).
+// - error: An error if the file cannot be read.
+//
+// Example Usage:
+// ```go
+// entropy, err := GetFileEntropy("/home/user/report.docx.locked", EntropyOptions{SampleBlocks: 8})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Entropy: %.2f bits/byte\n", entropy)
+//	}
+//
+// ```
+func GetFileEntropy(path string, options EntropyOptions) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	return fileEntropy(file, path, options)
+}
+
+// GetFileEntropyFS is GetFileEntropy for a file in any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Sampling needs random access; files that do not implement io.ReaderAt (e.g., compressed archive entries) are read whole.
+//
+// Parameters:
+// - fsys (fs.FS): The file system containing the file.
+// - name (string): The fsys name of the file (e.g., "home/user/report.docx.locked").
+// - options (EntropyOptions): Whole-file or sampled reading.
+//
+// Returns:
+// - float64: The entropy in bits per byte (0 for an empty file).
+// - error: An error if the file cannot be read.
+//
+// Example Usage:
+// ```go
+// entropy, err := GetFileEntropyFS(os.DirFS("/mnt/evidence"), "home/user/report.docx.locked", EntropyOptions{SampleBlocks: 8})
+// ```
+func GetFileEntropyFS(fsys fs.FS, name string, options EntropyOptions) (float64, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file %s: %v", name, err)
+	}
+	defer file.Close()
+
+	return fileEntropy(file, name, options)
+}
+
+// fileEntropy computes the entropy of an open file, named path in errors.
+func fileEntropy(file fs.File, path string, options EntropyOptions) (float64, error) {
+	if options.BlockSize <= 0 {
+		options.BlockSize = 64 * 1024
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to access file %s: %v", path, err)
+	}
+
+	var counts [256]int64
+	var total int64
+	count := func(reader io.Reader) error {
+		reader = throttleReader(reader)
+		buffer := make([]byte, 32*1024)
+		for {
+			n, err := reader.Read(buffer)
+			for _, b := range buffer[:n] {
+				counts[b]++
+			}
+			total += int64(n)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	blockSize := int64(options.BlockSize)
+	readerAt, canSample := file.(io.ReaderAt)
+	if !canSample || options.SampleBlocks <= 0 || info.Size() <= int64(options.SampleBlocks)*blockSize {
+		err = count(file)
+	} else {
+		step := (info.Size() - blockSize) / int64(max(options.SampleBlocks-1, 1))
+		for block := 0; block < options.SampleBlocks && err == nil; block++ {
+			err = count(io.NewSectionReader(readerAt, int64(block)*step, blockSize))
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file %s: %v", path, err)
+	}
+
+	return shannonEntropy(counts, total), nil
+}
+
+// FindHighEntropyFiles lists files whose contents look encrypted or packed.
+//
+// Description:
+// - Searches for files matching a pattern and computes each file's entropy with GetFileEntropy.
+// - Returns files at or above the minimum entropy, highest first.
+// - Formats that are compressed by nature (ZIP, JPEG, gzip, ...) are detected from their magic bytes and skipped unless includeCompressed is set.
+// - Files smaller than options.MinSize are skipped, since their entropy is not meaningful.
+//
+// Parameters:
+// - filesToFind (string): The pattern of files to analyze (e.g., "*").
+// - minEntropy (float64): The minimum entropy in bits per byte (e.g., 7.5).
+// - includeCompressed (bool): Whether to report naturally compressed formats too.
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+// - options (EntropyOptions): Sampling and minimum file size.
+//
+// Returns:
+// - []EntropyResult: The high-entropy files.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// results, err := FindHighEntropyFiles("*", 7.5, false, false, "/srv/share", EntropyOptions{SampleBlocks: 8})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, result := range results {
+//	        fmt.Printf("%.3f %s\n", result.Entropy, result.Path)
+//	    }
+//	}
+//
+// ```
+func FindHighEntropyFiles(filesToFind string, minEntropy float64, includeCompressed bool, searchAllDrives bool, checkThisDisk string, options EntropyOptions) ([]EntropyResult, error) {
+	var results []EntropyResult
+
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Measuring entropy in: %s\n", drive)
+		driveResults, err := findHighEntropyFiles(os.DirFS(drive), osPathFunc(drive), filesToFind, minEntropy, includeCompressed, options)
+		if err != nil {
+			return nil, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+		results = append(results, driveResults...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Entropy > results[j].Entropy })
+	return results, nil
+}
+
+// FindHighEntropyFilesFS is FindHighEntropyFiles for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; use fs.Sub to search a subdirectory.
+// - Result paths are fsys names (slash-separated, e.g., "share/report.docx.locked").
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - filesToFind (string): The pattern of files to analyze (e.g., "*").
+// - minEntropy (float64): The minimum entropy in bits per byte (e.g., 7.5).
+// - includeCompressed (bool): Whether to report naturally compressed formats too.
+// - options (EntropyOptions): Sampling and minimum file size.
+//
+// Returns:
+// - []EntropyResult: The high-entropy files, highest entropy first.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// results, err := FindHighEntropyFilesFS(os.DirFS("/mnt/evidence"), "*", 7.5, false, EntropyOptions{SampleBlocks: 8})
+// ```
+func FindHighEntropyFilesFS(fsys fs.FS, filesToFind string, minEntropy float64, includeCompressed bool, options EntropyOptions) ([]EntropyResult, error) {
+	results, err := findHighEntropyFiles(fsys, nil, filesToFind, minEntropy, includeCompressed, options)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Entropy > results[j].Entropy })
+	return results, nil
+}
+
+// findHighEntropyFiles measures the entropy of the files in fsys matching filesToFind.
+func findHighEntropyFiles(fsys fs.FS, toPath func(name string) string, filesToFind string, minEntropy float64, includeCompressed bool, options EntropyOptions) ([]EntropyResult, error) {
+	if options.MinSize <= 0 {
+		options.MinSize = 1024
+	}
+	var results []EntropyResult
+	err := walkFS(fsys, ".", toPath, WalkOptions{Include: []string{filesToFind}}, func(name, path string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() || info.Size() < options.MinSize {
+			return nil
+		}
+		fileType, err := DetectFileTypeFS(fsys, name)
+		if err != nil || (fileType.IsCompressed() && !includeCompressed) {
+			return nil
+		}
+		entropy, err := GetFileEntropyFS(fsys, name, options)
+		if err != nil || entropy < minEntropy {
+			return nil
+		}
+		results = append(results, EntropyResult{Path: path, Entropy: entropy, Size: info.Size(), DetectedType: fileType.Name})
+		return nil
+	})
+	return results, err
+}
+
+// shannonEntropy computes the entropy in bits per byte of a byte distribution.
+func shannonEntropy(counts [256]int64, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	var entropy float64
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
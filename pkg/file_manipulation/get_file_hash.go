@@ -0,0 +1,141 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// GetFileHash computes the hash of a file's contents.
+//
+// Description:
+// - Streams the file through the requested hash algorithm.
+// - Supported algorithms are "md5", "sha1" and "sha256" (the default when empty).
+//
+// Parameters:
+// - path (string): The file to hash.
+// - algorithm (string): The hash algorithm to use.
+//
+// Returns:
+// - string: The lowercase hex encoded hash.
+// - error: An error if the algorithm is unknown or the file cannot be read.
+//
+// Example Usage:
+// ```go
+// sum, err := GetFileHash("/etc/passwd", "sha256")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("SHA-256:", sum)
+//	}
+//
+// ```
+func GetFileHash(path, algorithm string) (string, error) {
+	if _, err := newHash(algorithm); err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	return hashReader(file, path, algorithm)
+}
+
+// GetFileHashFS is GetFileHash for a file in any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Parameters:
+// - fsys (fs.FS): The file system containing the file.
+// - name (string): The fsys name of the file (e.g., "etc/passwd").
+// - algorithm (string): The hash algorithm to use ("md5", "sha1" or "sha256").
+//
+// Returns:
+// - string: The lowercase hex encoded hash.
+// - error: An error if the algorithm is unknown or the file cannot be read.
+//
+// Example Usage:
+// ```go
+// sum, err := GetFileHashFS(os.DirFS("/mnt/evidence"), "etc/passwd", "sha256")
+// ```
+func GetFileHashFS(fsys fs.FS, name, algorithm string) (string, error) {
+	if _, err := newHash(algorithm); err != nil {
+		return "", err
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %v", name, err)
+	}
+	defer file.Close()
+
+	return hashReader(file, name, algorithm)
+}
+
+// hashReader hashes the contents of an open file, named path in errors.
+func hashReader(file io.Reader, path, algorithm string) (string, error) {
+	h, err := newHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, throttleReader(file)); err != nil {
+		return "", fmt.Errorf("failed to read file %s: %v", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newHash returns a hash.Hash for the named algorithm.
+func newHash(algorithm string) (hash.Hash, error) {
+	switch strings.ToLower(algorithm) {
+	case "", "sha256":
+		return sha256.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "md5":
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	}
+}
+
+// getFileHashes computes several hashes of a file in fsys in a single read.
+func getFileHashes(fsys fs.FS, name string, algorithms ...string) (map[string]string, error) {
+	hashes := make(map[string]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		h, err := newHash(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		hashes[algorithm] = h
+		writers = append(writers, h)
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %v", name, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(io.MultiWriter(writers...), throttleReader(file)); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", name, err)
+	}
+
+	sums := make(map[string]string, len(hashes))
+	for algorithm, h := range hashes {
+		sums[algorithm] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
@@ -4,10 +4,9 @@ package file_manipulation
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-
-	"github.com/Protheophage/GO/pkg/random_utilities"
 )
 
 // GetFilesCount counts the number of files matching specific criteria.
@@ -38,37 +37,48 @@ import (
 //
 // ```
 func GetFilesCount(filesToFind string, searchAllDrives bool, checkThisDisk string) (int, error) {
-	if checkThisDisk == "" {
-		if os.PathSeparator == '/' {
-			checkThisDisk = "/"
-		} else {
-			checkThisDisk = filepath.Join(os.Getenv("SystemDrive"), "")
-		}
-	}
-
-	var drives []string
-	if searchAllDrives {
-		drives = random_utilities.GetAllDrives()
-	} else {
-		drives = []string{checkThisDisk}
-	}
-
 	count := 0
-	for _, drive := range drives {
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
 		fmt.Printf("Counting files in: %s\n", drive)
-		err := filepath.Walk(drive, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip errors
-			}
-			if match, _ := filepath.Match(filesToFind, info.Name()); match {
-				count++
-			}
-			return nil
-		})
+		found, err := countFiles(os.DirFS(drive), filesToFind)
 		if err != nil {
 			return 0, fmt.Errorf("error processing drive %s: %v", drive, err)
 		}
+		count += found
 	}
 
 	return count, nil
 }
+
+// GetFilesCountFS is GetFilesCount for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Counts the matching entries in the whole of fsys; use fs.Sub to count a subdirectory.
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - filesToFind (string): The pattern of files to count (e.g., "*.log").
+//
+// Returns:
+// - int: The count of matching files.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// count, err := GetFilesCountFS(os.DirFS("/mnt/evidence"), "*.log")
+// ```
+func GetFilesCountFS(fsys fs.FS, filesToFind string) (int, error) {
+	return countFiles(fsys, filesToFind)
+}
+
+// countFiles counts the entries in fsys whose name matches a pattern.
+func countFiles(fsys fs.FS, filesToFind string) (int, error) {
+	count := 0
+	err := walkFS(fsys, ".", nil, WalkOptions{}, func(_, _ string, info fs.FileInfo) error {
+		if match, _ := filepath.Match(filesToFind, info.Name()); match {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,188 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// IOLimits caps the disk load of the functions that walk and read files.
+type IOLimits struct {
+	ReadBytesPerSecond int64   // Maximum read bandwidth across all file reads (0 = unlimited)
+	FilesPerSecond     float64 // Maximum number of files visited per second by the walker (0 = unlimited)
+}
+
+// LowImpactLimits are the limits EnableLowImpactMode applies when no limit has been set.
+var LowImpactLimits = IOLimits{ReadBytesPerSecond: 10 * 1024 * 1024, FilesPerSecond: 200}
+
+// throttleChunk is the largest read a throttled reader passes through at once, so waits stay short and even.
+const throttleChunk = 256 * 1024
+
+var ioThrottle struct {
+	sync.Mutex
+	limits IOLimits
+	read   *tokenBucket
+	files  *tokenBucket
+}
+
+// SetIOLimits sets the read bandwidth and file rate limits for all file_manipulation functions.
+//
+// Description:
+// - Both limits are token buckets that allow a burst of up to one second's worth of reads or files.
+// - WalkFiles waits for a file token before reporting each file, and file contents are read through the bandwidth limit.
+// - A zero limit removes that cap. The limits apply process-wide, including to calls already in progress.
+//
+// Parameters:
+// - limits (IOLimits): The read bandwidth and files-per-second caps.
+//
+// Returns: None
+//
+// Example Usage:
+// ```go
+// SetIOLimits(IOLimits{ReadBytesPerSecond: 20 * 1024 * 1024, FilesPerSecond: 500})
+// files, err := FindFilesByContent("password", ".conf", 1024, true, "")
+// ```
+func SetIOLimits(limits IOLimits) {
+	ioThrottle.Lock()
+	defer ioThrottle.Unlock()
+	ioThrottle.limits = limits
+	ioThrottle.read = newTokenBucket(float64(limits.ReadBytesPerSecond))
+	ioThrottle.files = newTokenBucket(limits.FilesPerSecond)
+}
+
+// GetIOLimits returns the limits set by SetIOLimits.
+//
+// Parameters: None
+//
+// Returns:
+// - IOLimits: The current limits; zero fields are unlimited.
+//
+// Example Usage:
+// ```go
+// limits := GetIOLimits()
+// fmt.Printf("Read cap: %d bytes/s, file cap: %.0f files/s\n", limits.ReadBytesPerSecond, limits.FilesPerSecond)
+// ```
+func GetIOLimits() IOLimits {
+	ioThrottle.Lock()
+	defer ioThrottle.Unlock()
+	return ioThrottle.limits
+}
+
+// EnableLowImpactMode makes the current process yield disk and CPU time to other work.
+//
+// Description:
+// - Applies LowImpactLimits to any limit that is not already set.
+// - On Linux, sets the IO scheduling class of every thread to idle and the CPU niceness to 19.
+// - On Windows, switches the process to background processing mode, which lowers both its CPU and IO priority.
+// - On other Unix systems, only the CPU niceness is lowered.
+//
+// Parameters: None
+//
+// Returns:
+// - error: An error if the process priority cannot be lowered; the IO limits are applied regardless.
+//
+// Example Usage:
+// ```go
+//
+//	if err := EnableLowImpactMode(); err != nil {
+//	    fmt.Println("Warning:", err)
+//	}
+//
+// ```
+func EnableLowImpactMode() error {
+	limits := GetIOLimits()
+	if limits.ReadBytesPerSecond <= 0 {
+		limits.ReadBytesPerSecond = LowImpactLimits.ReadBytesPerSecond
+	}
+	if limits.FilesPerSecond <= 0 {
+		limits.FilesPerSecond = LowImpactLimits.FilesPerSecond
+	}
+	SetIOLimits(limits)
+	return lowerProcessPriority()
+}
+
+// tokenBucket is a rate limiter that holds at most one second's worth of tokens.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket refilled at rate tokens per second, or nil (unlimited) for a zero rate.
+func newTokenBucket(rate float64) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{rate: rate, tokens: rate, last: time.Now()}
+}
+
+// take removes n tokens, sleeping until the bucket is no longer in debt. A nil bucket never waits.
+func (b *tokenBucket) take(n float64) {
+	if b == nil || n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= n
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+	time.Sleep(wait)
+}
+
+// waitForFile takes a token from the files-per-second limit.
+func waitForFile() {
+	ioThrottle.Lock()
+	bucket := ioThrottle.files
+	ioThrottle.Unlock()
+	bucket.take(1)
+}
+
+// waitForRead takes n bytes from the read bandwidth limit.
+func waitForRead(n int) {
+	ioThrottle.Lock()
+	bucket := ioThrottle.read
+	ioThrottle.Unlock()
+	bucket.take(float64(n))
+}
+
+// throttledReader counts reads against the read bandwidth limit.
+type throttledReader struct {
+	reader io.Reader
+}
+
+func (r throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+	n, err := r.reader.Read(p)
+	waitForRead(n)
+	return n, err
+}
+
+// throttleReader wraps a reader of file contents in the read bandwidth limit.
+// Without a limit the reader is returned unchanged, keeping io.Copy's fast paths.
+func throttleReader(reader io.Reader) io.Reader {
+	if GetIOLimits().ReadBytesPerSecond <= 0 {
+		return reader
+	}
+	return throttledReader{reader: reader}
+}
+
+// readFileFS is fs.ReadFile read through the read bandwidth limit.
+func readFileFS(fsys fs.FS, name string) ([]byte, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(throttleReader(file))
+}
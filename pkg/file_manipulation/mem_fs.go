@@ -0,0 +1,308 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory WritableFS, for running the file_manipulation functions against fake trees.
+// It is safe for concurrent use. It has no symbolic links, owners or access times.
+type MemFS struct {
+	mu    sync.RWMutex
+	files map[string]*memEntry // By name; the root "." is implicit
+}
+
+// memEntry is a file or directory stored in a MemFS. Data is replaced, never modified in place, so open files keep a consistent view.
+type memEntry struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemFS returns an empty in-memory file system.
+//
+// Description:
+// - Build a tree with WriteFile and MkdirAll, then pass it to the ...FS variants of the file_manipulation functions.
+// - Parent directories are created implicitly by WriteFile, unlike on a real disk.
+//
+// Parameters: None
+//
+// Returns:
+// - *MemFS: The file system.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// fsys.WriteFile("logs/app.log", []byte("connection refused\n"), 0o644)
+// fsys.WriteFile("logs/app.tmp", nil, 0o644)
+// files, err := FindFilesFS(fsys, "*.log")
+// ```
+func NewMemFS() *MemFS {
+	return &MemFS{files: map[string]*memEntry{}}
+}
+
+// Open opens the named file or directory for reading.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	info, ok := m.stat(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if !info.IsDir() {
+		return &memFile{info: info, reader: bytes.NewReader(m.files[name].data)}, nil
+	}
+	var entries []fs.DirEntry
+	for _, child := range m.children(name) {
+		childInfo, _ := m.stat(child)
+		entries = append(entries, fs.FileInfoToDirEntry(childInfo))
+	}
+	return &memDir{info: info, entries: entries}, nil
+}
+
+// Stat returns the FileInfo of the named file or directory.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	info, ok := m.stat(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return info, nil
+}
+
+// ReadFile returns a copy of the named file's contents.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	info, ok := m.stat(name)
+	switch {
+	case !ok:
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	case info.IsDir():
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return append([]byte(nil), m.files[name].data...), nil
+}
+
+// ReadDir reads the named directory, sorted by file name.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	file, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	dir, ok := file.(*memDir)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return dir.entries, nil
+}
+
+// WriteFile creates or replaces the named file, creating missing parent directories.
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.mkdirAll(path.Dir(name), 0o755); err != nil {
+		return err
+	}
+	if info, ok := m.stat(name); ok && info.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	}
+	m.files[name] = &memEntry{data: append([]byte(nil), data...), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// MkdirAll creates the named directory and any missing parents.
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(name, perm)
+}
+
+// Remove removes the named file or empty directory.
+func (m *MemFS) Remove(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, ok := m.stat(name)
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if info.IsDir() && len(m.children(name)) > 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// Rename renames (moves) a file or directory. An existing file at newName is replaced.
+func (m *MemFS) Rename(oldName, newName string) error {
+	for _, name := range []string{oldName, newName} {
+		if !fs.ValidPath(name) || name == "." {
+			return &fs.PathError{Op: "rename", Path: name, Err: fs.ErrInvalid}
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info, ok := m.stat(oldName)
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldName, Err: fs.ErrNotExist}
+	}
+	if oldName == newName {
+		return nil
+	}
+	if parent, ok := m.stat(path.Dir(newName)); !ok || !parent.IsDir() {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrNotExist}
+	}
+	if target, ok := m.stat(newName); ok && target.IsDir() {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrExist}
+	}
+	if info.IsDir() && strings.HasPrefix(newName, oldName+"/") {
+		return &fs.PathError{Op: "rename", Path: newName, Err: errors.New("cannot move a directory into itself")}
+	}
+
+	moved := map[string]string{oldName: newName}
+	if info.IsDir() {
+		for name := range m.files {
+			if strings.HasPrefix(name, oldName+"/") {
+				moved[name] = newName + strings.TrimPrefix(name, oldName)
+			}
+		}
+	}
+	entries := make(map[string]*memEntry, len(moved))
+	for from := range moved {
+		entries[from] = m.files[from]
+		delete(m.files, from)
+	}
+	for from, to := range moved {
+		m.files[to] = entries[from]
+	}
+	return nil
+}
+
+// stat returns the FileInfo of a name. The caller must hold the lock.
+func (m *MemFS) stat(name string) (fs.FileInfo, bool) {
+	if name == "." {
+		return memFileInfo{name: ".", mode: fs.ModeDir | 0o755}, true
+	}
+	entry, ok := m.files[name]
+	if !ok {
+		return nil, false
+	}
+	return memFileInfo{name: path.Base(name), size: int64(len(entry.data)), mode: entry.mode, modTime: entry.modTime}, true
+}
+
+// children returns the names of the entries directly inside a directory, sorted. The caller must hold the lock.
+func (m *MemFS) children(dir string) []string {
+	var names []string
+	for name := range m.files {
+		if path.Dir(name) == dir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mkdirAll creates a directory and its missing parents. The caller must hold the write lock.
+func (m *MemFS) mkdirAll(name string, perm fs.FileMode) error {
+	if info, ok := m.stat(name); ok {
+		if !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+		return nil
+	}
+	if err := m.mkdirAll(path.Dir(name), perm); err != nil {
+		return err
+	}
+	m.files[name] = &memEntry{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// memFileInfo describes a MemFS entry.
+type memFileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (info memFileInfo) Name() string       { return info.name }
+func (info memFileInfo) Size() int64        { return info.size }
+func (info memFileInfo) Mode() fs.FileMode  { return info.mode }
+func (info memFileInfo) ModTime() time.Time { return info.modTime }
+func (info memFileInfo) IsDir() bool        { return info.mode.IsDir() }
+func (info memFileInfo) Sys() any           { return nil }
+
+// memFile is an open MemFS file. It supports Seek and ReadAt, so sampled reads work as on a real file.
+type memFile struct {
+	info   fs.FileInfo
+	reader *bytes.Reader
+}
+
+func (file *memFile) Stat() (fs.FileInfo, error) { return file.info, nil }
+func (file *memFile) Read(buffer []byte) (int, error) {
+	return file.reader.Read(buffer)
+}
+func (file *memFile) ReadAt(buffer []byte, offset int64) (int, error) {
+	return file.reader.ReadAt(buffer, offset)
+}
+func (file *memFile) Seek(offset int64, whence int) (int64, error) {
+	return file.reader.Seek(offset, whence)
+}
+func (file *memFile) Close() error { return nil }
+
+// memDir is an open MemFS directory. Its entries are read when it is opened.
+type memDir struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (dir *memDir) Stat() (fs.FileInfo, error) { return dir.info, nil }
+func (dir *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: dir.info.Name(), Err: errors.New("is a directory")}
+}
+func (dir *memDir) Close() error { return nil }
+
+// ReadDir returns the next n entries, or all remaining entries if n <= 0, as fs.ReadDirFile requires.
+func (dir *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := dir.entries[dir.offset:]
+	if n <= 0 {
+		dir.offset = len(dir.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	dir.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,33 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemFS(t *testing.T) {
+	fsys := NewMemFS()
+	files := map[string]string{
+		"readme.txt":           "hello\n",
+		"logs/app.log":         "connection refused\n",
+		"logs/archive/old.log": "",
+		"data/report.bin":      "\x00\x01\x02\x03",
+	}
+	for name, data := range files {
+		if err := fsys.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatalf("WriteFile(%q): %v", name, err)
+		}
+	}
+	if err := fsys.MkdirAll("empty/dir", 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := fsys.Rename("data/report.bin", "data/report.dat"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	if err := fstest.TestFS(fsys, "readme.txt", "logs/app.log", "logs/archive/old.log", "data/report.dat", "empty/dir"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,42 @@
+// This module is Linux-specific.
+
+//go:build linux
+
+package file_manipulation
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// ioprio_set arguments (see ioprio_set(2)); these are not defined in x/sys/unix.
+const (
+	ioprioWhoProcess = 1
+	ioprioClassIdle  = 3
+	ioprioClassShift = 13
+)
+
+// lowerProcessPriority sets the idle IO class and niceness 19 on every thread of the process.
+// Both are per-thread on Linux; threads started later inherit them from the thread that creates them.
+func lowerProcessPriority() error {
+	tasks, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return fmt.Errorf("failed to list threads: %v", err)
+	}
+	for _, task := range tasks {
+		tid, err := strconv.Atoi(task.Name())
+		if err != nil {
+			continue
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), ioprioClassIdle<<ioprioClassShift); errno != 0 && errno != unix.ESRCH {
+			return fmt.Errorf("failed to set idle IO priority: %v", errno)
+		}
+		if err := unix.Setpriority(unix.PRIO_PROCESS, tid, 19); err != nil && err != unix.ESRCH {
+			return fmt.Errorf("failed to lower CPU priority: %v", err)
+		}
+	}
+	return nil
+}
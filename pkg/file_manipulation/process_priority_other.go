@@ -0,0 +1,19 @@
+// This module is for Unix platforms other than Linux (e.g., macOS).
+
+//go:build !linux && !windows
+
+package file_manipulation
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// lowerProcessPriority sets the process niceness to 19. IO priority is left unchanged.
+func lowerProcessPriority() error {
+	if err := unix.Setpriority(unix.PRIO_PROCESS, 0, 19); err != nil {
+		return fmt.Errorf("failed to lower CPU priority: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+// This module is Windows-specific.
+
+//go:build windows
+
+package file_manipulation
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// lowerProcessPriority switches the process to background mode, which lowers its CPU, IO and memory priority.
+func lowerProcessPriority() error {
+	if err := windows.SetPriorityClass(windows.CurrentProcess(), windows.PROCESS_MODE_BACKGROUND_BEGIN); err != nil {
+		return fmt.Errorf("failed to enter background processing mode: %v", err)
+	}
+	return nil
+}
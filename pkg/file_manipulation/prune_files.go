@@ -0,0 +1,336 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RetentionPolicy describes which files to keep when pruning. Zero values disable a rule.
+type RetentionPolicy struct {
+	Patterns     []string      // File name patterns the policy applies to (e.g., "*.log")
+	MaxAge       time.Duration // Remove files older than this
+	KeepLast     int           // Always keep the newest N files in each group
+	MaxTotalSize int64         // Trim each group to this many bytes, removing the oldest files first
+	GroupBy      string        // "directory" (default) or "pattern"
+}
+
+// PruneCandidate is a file selected for removal by a prune plan.
+type PruneCandidate struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	Group   string    `json:"group"`
+	Reason  string    `json:"reason"`
+}
+
+// PrunePlan lists the files a retention policy would remove.
+type PrunePlan struct {
+	Root       string
+	Policy     RetentionPolicy
+	CreatedAt  time.Time
+	Remove     []PruneCandidate
+	Kept       int   // Number of matching files the policy keeps
+	FreedBytes int64 // Total size of the files to remove
+}
+
+// pruneAuditRecord is one line of the prune audit log.
+type pruneAuditRecord struct {
+	Time time.Time `json:"time"`
+	PruneCandidate
+	Result string `json:"result"` // "removed" or the error message
+}
+
+// DefaultPruneAuditPath returns the default location of the prune audit log.
+//
+// Description:
+// - Uses the platform user cache directory (e.g., "%LocalAppData%" on Windows, "~/.cache" on Linux).
+// - Falls back to the system temp directory if the cache directory cannot be determined.
+//
+// Parameters: None
+//
+// Returns:
+// - string: The default audit log path.
+//
+// Example Usage:
+// ```go
+// fmt.Println("Audit log:", DefaultPruneAuditPath())
+// ```
+func DefaultPruneAuditPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "file-manager", "prune-audit.jsonl")
+}
+
+// PlanPrune builds the list of files a retention policy would remove.
+//
+// Description:
+// - Walks the root and groups files matching the policy patterns per directory or per pattern.
+// - Within each group, the newest KeepLast files are always kept.
+// - Other files are removed if they are older than MaxAge, or if keeping them would exceed MaxTotalSize.
+// - Newer files count against the size budget first; from the first file that does not fit, all older files are removed.
+// - Nothing is removed; pass the plan to ApplyPrunePlan to act on it.
+//
+// Parameters:
+// - root (string): The directory to prune.
+// - policy (RetentionPolicy): The age, count and size rules.
+//
+// Returns:
+// - PrunePlan: The files to remove and the number of files kept.
+// - error: An error if the policy is invalid or the root cannot be walked.
+//
+// Example Usage:
+// ```go
+// plan, err := PlanPrune("/var/log/app", RetentionPolicy{Patterns: []string{"*.log"}, MaxAge: 14 * 24 * time.Hour, KeepLast: 5})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Would remove %d files (%d bytes).\n", len(plan.Remove), plan.FreedBytes)
+//	}
+//
+// ```
+func PlanPrune(root string, policy RetentionPolicy) (PrunePlan, error) {
+	plan, err := newPrunePlan(root, policy)
+	if err != nil {
+		return plan, err
+	}
+
+	fmt.Printf("Planning prune in: %s\n", root)
+	if err := planPrune(&plan, os.DirFS(root), osPathFunc(root)); err != nil {
+		return plan, fmt.Errorf("error processing directory %s: %v", root, err)
+	}
+	return plan, nil
+}
+
+// PlanPruneFS is PlanPrune for any fs.FS, such as a mounted image or a MemFS.
+//
+// Description:
+// - Plans the prune of the whole of fsys; use fs.Sub to prune a subdirectory.
+// - Candidate paths and directory groups are fsys names (slash-separated, e.g., "app/old.log"), and Root is left empty.
+// - Pass the plan to ApplyPrunePlanFS with the same file system.
+//
+// Parameters:
+// - fsys (fs.FS): The file system to prune.
+// - policy (RetentionPolicy): The age, count and size rules.
+//
+// Returns:
+// - PrunePlan: The files to remove and the number of files kept.
+// - error: An error if the policy is invalid or fsys cannot be walked.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// fsys.WriteFile("app/old.log", make([]byte, 4096), 0o644)
+// plan, err := PlanPruneFS(fsys, RetentionPolicy{Patterns: []string{"*.log"}, MaxTotalSize: 1024})
+// ```
+func PlanPruneFS(fsys fs.FS, policy RetentionPolicy) (PrunePlan, error) {
+	plan, err := newPrunePlan("", policy)
+	if err != nil {
+		return plan, err
+	}
+	if err := planPrune(&plan, fsys, nil); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// newPrunePlan validates a retention policy and fills in its defaults.
+func newPrunePlan(root string, policy RetentionPolicy) (PrunePlan, error) {
+	plan := PrunePlan{Root: root, Policy: policy, CreatedAt: time.Now()}
+
+	if len(policy.Patterns) == 0 {
+		return plan, fmt.Errorf("retention policy needs at least one file pattern")
+	}
+	if policy.MaxAge <= 0 && policy.MaxTotalSize <= 0 {
+		return plan, fmt.Errorf("retention policy needs a maximum age or a total size budget")
+	}
+	if policy.GroupBy == "" {
+		plan.Policy.GroupBy = "directory"
+	}
+	if plan.Policy.GroupBy != "directory" && plan.Policy.GroupBy != "pattern" {
+		return plan, fmt.Errorf("invalid group option: %s", policy.GroupBy)
+	}
+	return plan, nil
+}
+
+// planPrune walks fsys and adds the files to remove to the plan, with paths converted with toPath.
+func planPrune(plan *PrunePlan, fsys fs.FS, toPath func(name string) string) error {
+	policy := plan.Policy
+	groups := map[string][]PruneCandidate{}
+	err := walkFS(fsys, ".", toPath, WalkOptions{}, func(name, filePath string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		for _, pattern := range policy.Patterns {
+			if match, _ := filepath.Match(pattern, info.Name()); !match {
+				continue
+			}
+			group := path.Dir(name)
+			if toPath != nil {
+				group = toPath(group)
+			}
+			if policy.GroupBy == "pattern" {
+				group = pattern
+			}
+			groups[group] = append(groups[group], PruneCandidate{Path: filePath, Size: info.Size(), ModTime: info.ModTime(), Group: group})
+			break
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, files := range groups {
+		// Newest first, so the files kept by count and size budgets are the most recent ones.
+		sort.Slice(files, func(i, j int) bool { return files[i].ModTime.After(files[j].ModTime) })
+
+		var keptBytes int64
+		overBudget := false // Once a file does not fit, every older file goes too, even if a smaller one would fit
+		for i, file := range files {
+			switch {
+			case i < policy.KeepLast:
+				// Always kept
+			case policy.MaxAge > 0 && plan.CreatedAt.Sub(file.ModTime) > policy.MaxAge:
+				file.Reason = fmt.Sprintf("older than %s", policy.MaxAge)
+			case policy.MaxTotalSize > 0 && (overBudget || keptBytes+file.Size > policy.MaxTotalSize):
+				overBudget = true
+				file.Reason = fmt.Sprintf("exceeds size budget of %d bytes", policy.MaxTotalSize)
+			}
+
+			if file.Reason == "" {
+				keptBytes += file.Size
+				plan.Kept++
+				continue
+			}
+			plan.Remove = append(plan.Remove, file)
+			plan.FreedBytes += file.Size
+		}
+	}
+
+	sort.Slice(plan.Remove, func(i, j int) bool { return plan.Remove[i].Path < plan.Remove[j].Path })
+	return nil
+}
+
+// ApplyPrunePlan removes the files in a prune plan and records each removal in an audit log.
+//
+// Description:
+// - Skips files whose size or modification time changed since the plan was made.
+// - Appends one JSON line per file to the audit log with the time, path, size, reason and result.
+//
+// Parameters:
+// - plan (PrunePlan): The plan returned by PlanPrune.
+// - auditPath (string): The audit log to append to (use "" for DefaultPruneAuditPath()).
+//
+// Returns:
+// - []PruneCandidate: The files that were removed.
+// - error: An error if the audit log cannot be written.
+//
+// Example Usage:
+// ```go
+// removed, err := ApplyPrunePlan(plan, "")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Printf("Removed %d files.\n", len(removed))
+//	}
+//
+// ```
+func ApplyPrunePlan(plan PrunePlan, auditPath string) ([]PruneCandidate, error) {
+	if auditPath == "" {
+		auditPath = DefaultPruneAuditPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(auditPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+	audit, err := os.OpenFile(auditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %v", auditPath, err)
+	}
+	defer audit.Close()
+	encoder := json.NewEncoder(audit)
+
+	return applyPrunePlan(plan, os.Lstat, os.Remove, func(record pruneAuditRecord) error {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write audit log %s: %v", auditPath, err)
+		}
+		return nil
+	})
+}
+
+// ApplyPrunePlanFS is ApplyPrunePlan for a WritableFS, such as an OSFS or a MemFS.
+//
+// Description:
+// - Removes the files of a plan made by PlanPruneFS on the same file system.
+// - Skips files whose size or modification time changed since the plan was made.
+// - Writes the audit records as JSON lines to audit, which may be nil to skip the audit log.
+//
+// Parameters:
+// - fsys (WritableFS): The file system to change.
+// - plan (PrunePlan): The plan returned by PlanPruneFS.
+// - audit (io.Writer): Where to write the audit records (use nil for none).
+//
+// Returns:
+// - []PruneCandidate: The files that were removed.
+// - error: An error if the audit records cannot be written.
+//
+// Example Usage:
+// ```go
+// plan, _ := PlanPruneFS(fsys, RetentionPolicy{Patterns: []string{"*.log"}, MaxAge: 14 * 24 * time.Hour})
+// removed, err := ApplyPrunePlanFS(fsys, plan, os.Stdout)
+// ```
+func ApplyPrunePlanFS(fsys WritableFS, plan PrunePlan, audit io.Writer) ([]PruneCandidate, error) {
+	stat := func(name string) (fs.FileInfo, error) { return fs.Stat(fsys, name) }
+	return applyPrunePlan(plan, stat, fsys.Remove, func(record pruneAuditRecord) error {
+		if audit == nil {
+			return nil
+		}
+		if err := json.NewEncoder(audit).Encode(record); err != nil {
+			return fmt.Errorf("failed to write audit log: %v", err)
+		}
+		return nil
+	})
+}
+
+// applyPrunePlan removes the files of a plan with remove, after checking them with stat, and passes each result to record.
+func applyPrunePlan(plan PrunePlan, stat func(name string) (fs.FileInfo, error), remove func(name string) error, record func(pruneAuditRecord) error) ([]PruneCandidate, error) {
+	var removed []PruneCandidate
+	for _, file := range plan.Remove {
+		result := pruneAuditRecord{Time: time.Now().UTC(), PruneCandidate: file, Result: "removed"}
+
+		info, err := stat(file.Path)
+		switch {
+		case err != nil:
+			result.Result = err.Error()
+		case info.Size() != file.Size || !info.ModTime().Equal(file.ModTime):
+			result.Result = "skipped: file changed since the plan was made"
+		default:
+			if err := remove(file.Path); err != nil {
+				result.Result = err.Error()
+				fmt.Printf("Failed to remove file: %s. Error: %v\n", file.Path, err)
+			} else {
+				removed = append(removed, file)
+			}
+		}
+
+		if err := record(result); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
@@ -4,10 +4,8 @@ package file_manipulation
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
-
-	"github.com/Protheophage/GO/pkg/random_utilities"
 )
 
 // RemoveFiles deletes files matching specific criteria.
@@ -37,38 +35,52 @@ import (
 //
 // ```
 func RemoveFiles(filesToDelete string, searchAllDrives bool, checkThisDisk string) error {
-	if checkThisDisk == "" {
-		if os.PathSeparator == '/' {
-			checkThisDisk = "/"
-		} else {
-			checkThisDisk = filepath.Join(os.Getenv("SystemDrive"), "")
-		}
-	}
-
-	var drives []string
-	if searchAllDrives {
-		drives = random_utilities.GetAllDrives()
-	} else {
-		drives = []string{checkThisDisk}
-	}
-
-	for _, drive := range drives {
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
 		fmt.Printf("Removing files in: %s\n", drive)
-		err := filepath.Walk(drive, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip errors
-			}
-			if match, _ := filepath.Match(filesToDelete, info.Name()); match {
-				if err := os.Remove(path); err != nil {
-					fmt.Printf("Failed to remove file: %s. Error: %v\n", path, err)
-				}
-			}
-			return nil
-		})
-		if err != nil {
+		if _, err := removeFiles(NewOSFS(drive), osPathFunc(drive), filesToDelete); err != nil {
 			return fmt.Errorf("error processing drive %s: %v", drive, err)
 		}
 	}
 
 	return nil
 }
+
+// RemoveFilesFS is RemoveFiles for a WritableFS, such as an OSFS or a MemFS.
+//
+// Description:
+// - Removes the matching entries in the whole of fsys.
+// - Failures are printed and the remaining files are still processed.
+//
+// Parameters:
+// - fsys (WritableFS): The file system to change.
+// - filesToDelete (string): The pattern of files to delete (e.g., "*.tmp").
+//
+// Returns:
+// - []string: The names of the removed files.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// fsys.WriteFile("cache/a.tmp", nil, 0o644)
+// removed, err := RemoveFilesFS(fsys, "*.tmp")
+// ```
+func RemoveFilesFS(fsys WritableFS, filesToDelete string) ([]string, error) {
+	return removeFiles(fsys, nil, filesToDelete)
+}
+
+// removeFiles removes the entries in fsys whose name matches a pattern and returns their paths, converted with toPath.
+func removeFiles(fsys WritableFS, toPath func(name string) string, filesToDelete string) ([]string, error) {
+	var removed []string
+	err := walkFS(fsys, ".", toPath, WalkOptions{}, func(name, path string, info fs.FileInfo) error {
+		if match, _ := filepath.Match(filesToDelete, info.Name()); match {
+			if err := fsys.Remove(name); err != nil {
+				fmt.Printf("Failed to remove file: %s. Error: %v\n", path, err)
+				return nil
+			}
+			removed = append(removed, path)
+		}
+		return nil
+	})
+	return removed, err
+}
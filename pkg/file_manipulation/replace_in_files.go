@@ -0,0 +1,374 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// ReplaceOptions controls PlanReplacements and ApplyReplacements.
+type ReplaceOptions struct {
+	Regex       bool  // Treat the search pattern as a regular expression; the replacement may use $1 or ${name}
+	Backup      bool  // Keep the original contents as <file>.bak
+	MaxFileSize int64 // Skip files larger than this (default: 10 MB)
+}
+
+// FileReplacement is the planned change to one file.
+type FileReplacement struct {
+	Path         string
+	Replacements int    // Number of matches replaced
+	Diff         string // Unified diff of the change
+
+	original []byte
+	updated  []byte
+}
+
+// ReplacePlan lists the files a replacement would change and the files it skipped.
+type ReplacePlan struct {
+	Changes []FileReplacement
+	Skipped []string // Files that were not changed, with the reason (e.g., binary files)
+}
+
+// PlanReplacements computes a search-and-replace across files without writing anything.
+//
+// Description:
+// - Searches for files matching a pattern and replaces a literal string, or a regular expression with capture groups, in each one.
+// - Returns each changed file with a unified diff, so the change can be reviewed before ApplyReplacements writes it.
+// - Binary files (containing NUL bytes) and files larger than MaxFileSize are refused and listed as skipped.
+//
+// Parameters:
+// - filesToFind (string): The pattern of files to edit (e.g., "*.conf").
+// - search (string): The literal string or regular expression to replace.
+// - replacement (string): The replacement text; with options.Regex, "$1" and "${name}" expand to capture groups.
+// - options (ReplaceOptions): Regex mode, backups and size limit.
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+//
+// Returns:
+// - ReplacePlan: The files that would change, with diffs, and the skipped files.
+// - error: An error if the regular expression is invalid or the search fails.
+//
+// Example Usage:
+// ```go
+// plan, err := PlanReplacements("*.conf", `listen (\d+)`, "listen 8${1}", ReplaceOptions{Regex: true}, false, "/etc/app")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, change := range plan.Changes {
+//	        fmt.Print(change.Diff)
+//	    }
+//	}
+//
+// ```
+func PlanReplacements(filesToFind, search, replacement string, options ReplaceOptions, searchAllDrives bool, checkThisDisk string) (ReplacePlan, error) {
+	var plan ReplacePlan
+	replace, err := newReplacer(search, replacement, &options)
+	if err != nil {
+		return plan, err
+	}
+
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Searching for files to edit in: %s\n", drive)
+		if err := planReplacements(&plan, os.DirFS(drive), osPathFunc(drive), filesToFind, options, replace); err != nil {
+			return plan, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+	}
+
+	return plan, nil
+}
+
+// PlanReplacementsFS is PlanReplacements for any fs.FS, such as a mounted image or a MemFS.
+//
+// Description:
+// - Searches the whole of fsys; use fs.Sub to edit a subdirectory.
+// - Change and skipped paths are fsys names (slash-separated, e.g., "app/site.conf").
+// - Pass the plan to ApplyReplacementsFS with the same file system.
+//
+// Parameters:
+// - fsys (fs.FS): The file system to search.
+// - filesToFind (string): The pattern of files to edit (e.g., "*.conf").
+// - search (string): The literal string or regular expression to replace.
+// - replacement (string): The replacement text; with options.Regex, "$1" and "${name}" expand to capture groups.
+// - options (ReplaceOptions): Regex mode, backups and size limit.
+//
+// Returns:
+// - ReplacePlan: The files that would change, with diffs, and the skipped files.
+// - error: An error if the regular expression is invalid or the search fails.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// fsys.WriteFile("app/site.conf", []byte("listen 80\n"), 0o644)
+// plan, err := PlanReplacementsFS(fsys, "*.conf", "listen 80", "listen 8080", ReplaceOptions{})
+// ```
+func PlanReplacementsFS(fsys fs.FS, filesToFind, search, replacement string, options ReplaceOptions) (ReplacePlan, error) {
+	var plan ReplacePlan
+	replace, err := newReplacer(search, replacement, &options)
+	if err != nil {
+		return plan, err
+	}
+	if err := planReplacements(&plan, fsys, nil, filesToFind, options, replace); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// newReplacer validates a search and fills in the default size limit. The returned function
+// applies the replacement to a file's contents and counts the matches.
+func newReplacer(search, replacement string, options *ReplaceOptions) (func(original []byte) ([]byte, int), error) {
+	if search == "" {
+		return nil, fmt.Errorf("search pattern cannot be empty")
+	}
+	if options.MaxFileSize <= 0 {
+		options.MaxFileSize = 10 * 1024 * 1024
+	}
+
+	if options.Regex {
+		regex, err := regexp.Compile(search)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		return func(original []byte) ([]byte, int) {
+			return regex.ReplaceAll(original, []byte(replacement)), len(regex.FindAllIndex(original, -1))
+		}, nil
+	}
+	return func(original []byte) ([]byte, int) {
+		return bytes.ReplaceAll(original, []byte(search), []byte(replacement)), bytes.Count(original, []byte(search))
+	}, nil
+}
+
+// planReplacements adds the files in fsys that the replacement would change to the plan, with paths converted with toPath.
+func planReplacements(plan *ReplacePlan, fsys fs.FS, toPath func(name string) string, filesToFind string, options ReplaceOptions, replace func([]byte) ([]byte, int)) error {
+	return walkFS(fsys, ".", toPath, WalkOptions{Include: []string{filesToFind}}, func(name, path string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if info.Size() > options.MaxFileSize {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s: larger than %d bytes", path, options.MaxFileSize))
+			return nil
+		}
+		original, err := readFileFS(fsys, name)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+
+		updated, count := replace(original)
+		if count == 0 || bytes.Equal(original, updated) {
+			return nil
+		}
+		if bytes.IndexByte(original, 0) >= 0 {
+			plan.Skipped = append(plan.Skipped, fmt.Sprintf("%s: binary file", path))
+			return nil
+		}
+
+		plan.Changes = append(plan.Changes, FileReplacement{
+			Path:         path,
+			Replacements: count,
+			Diff:         UnifiedDiff(path, string(original), string(updated)),
+			original:     original,
+			updated:      updated,
+		})
+		return nil
+	})
+}
+
+// ApplyReplacements writes the changes in a replacement plan.
+//
+// Description:
+// - Writes each file atomically: the new contents go to a temporary file in the same directory, which is renamed over the original.
+// - The original file's mode and owner are kept; a file whose owner cannot be restored is left unchanged.
+// - With options.Backup, the original contents are saved as <file>.bak first.
+// - Files that changed since the plan was made are skipped.
+//
+// Parameters:
+// - plan (ReplacePlan): The plan returned by PlanReplacements.
+// - options (ReplaceOptions): Whether to keep backups.
+//
+// Returns:
+// - []string: The files that were written.
+// - []string: The files that could not be written, with the reason.
+//
+// Example Usage:
+// ```go
+// written, failed := ApplyReplacements(plan, ReplaceOptions{Backup: true})
+// fmt.Printf("Updated %d files, %d failed.\n", len(written), len(failed))
+// ```
+func ApplyReplacements(plan ReplacePlan, options ReplaceOptions) ([]string, []string) {
+	var written, failed []string
+	for _, change := range plan.Changes {
+		if err := writeReplacement(change, options.Backup); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", change.Path, err))
+			continue
+		}
+		written = append(written, change.Path)
+	}
+	return written, failed
+}
+
+// ApplyReplacementsFS is ApplyReplacements for a WritableFS, such as an OSFS or a MemFS.
+//
+// Description:
+// - Writes the changes of a plan made by PlanReplacementsFS on the same file system.
+// - The new contents go to a temporary file next to the original, which is renamed over it; the mode is kept, but not the owner.
+// - With options.Backup, the original contents are saved as <file>.bak first.
+// - Files that changed since the plan was made are skipped.
+//
+// Parameters:
+// - fsys (WritableFS): The file system to change.
+// - plan (ReplacePlan): The plan returned by PlanReplacementsFS.
+// - options (ReplaceOptions): Whether to keep backups.
+//
+// Returns:
+// - []string: The files that were written.
+// - []string: The files that could not be written, with the reason.
+//
+// Example Usage:
+// ```go
+// written, failed := ApplyReplacementsFS(fsys, plan, ReplaceOptions{Backup: true})
+// ```
+func ApplyReplacementsFS(fsys WritableFS, plan ReplacePlan, options ReplaceOptions) ([]string, []string) {
+	var written, failed []string
+	for _, change := range plan.Changes {
+		if err := writeReplacementFS(fsys, change, options.Backup); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", change.Path, err))
+			continue
+		}
+		written = append(written, change.Path)
+	}
+	return written, failed
+}
+
+// writeReplacementFS replaces a file's contents in fsys through a temporary file, keeping its mode.
+func writeReplacementFS(fsys WritableFS, change FileReplacement, backup bool) error {
+	info, err := fs.Stat(fsys, change.Path)
+	if err != nil {
+		return err
+	}
+	current, err := fs.ReadFile(fsys, change.Path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, change.original) {
+		return fmt.Errorf("file changed since the replacement was planned")
+	}
+
+	if backup {
+		if err := replaceFileFS(fsys, change.Path+".bak", change.original, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write backup: %v", err)
+		}
+	}
+	return replaceFileFS(fsys, change.Path, change.updated, info.Mode().Perm())
+}
+
+// replaceFileFS writes data to a temporary file next to name and renames it over name, so an
+// existing file or link at name is replaced rather than written through.
+func replaceFileFS(fsys WritableFS, name string, data []byte, perm fs.FileMode) error {
+	tempName := path.Join(path.Dir(name), fmt.Sprintf(".%s.%d.tmp", path.Base(name), time.Now().UnixNano()))
+	if err := fsys.WriteFile(tempName, data, perm); err != nil {
+		fsys.Remove(tempName)
+		return fmt.Errorf("failed to write temporary file: %v", err)
+	}
+	if err := fsys.Rename(tempName, name); err != nil {
+		fsys.Remove(tempName)
+		return fmt.Errorf("failed to replace file: %v", err)
+	}
+	return nil
+}
+
+// writeReplacement atomically replaces a file's contents, keeping its mode and owner.
+func writeReplacement(change FileReplacement, backup bool) error {
+	info, err := os.Lstat(change.Path)
+	if err != nil {
+		return err
+	}
+	current, err := os.ReadFile(change.Path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, change.original) {
+		return fmt.Errorf("file changed since the replacement was planned")
+	}
+
+	if backup {
+		if err := writeBackup(change.Path+".bak", change.original, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write backup: %v", err)
+		}
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(change.Path), "."+filepath.Base(change.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	tempPath := temp.Name()
+	cleanup := func(err error) error {
+		temp.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if _, err := temp.Write(change.updated); err != nil {
+		return cleanup(fmt.Errorf("failed to write temporary file: %v", err))
+	}
+	if err := temp.Sync(); err != nil {
+		return cleanup(fmt.Errorf("failed to flush temporary file: %v", err))
+	}
+	// Change the owner first, since chown clears the setuid and setgid bits.
+	if ownership := getFileOwnership(info); ownership.Known {
+		if err := temp.Chown(ownership.UID, ownership.GID); err != nil {
+			return cleanup(fmt.Errorf("failed to keep file owner %d:%d: %v", ownership.UID, ownership.GID, err))
+		}
+	}
+	if err := temp.Chmod(info.Mode().Perm() | info.Mode()&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky)); err != nil {
+		return cleanup(fmt.Errorf("failed to set file mode: %v", err))
+	}
+	if err := temp.Close(); err != nil {
+		return cleanup(fmt.Errorf("failed to close temporary file: %v", err))
+	}
+
+	if err := os.Rename(tempPath, change.Path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to replace file: %v", err)
+	}
+	return nil
+}
+
+// writeBackup creates a backup file through a new temporary file renamed over backupPath, so a
+// symbolic link or an old file at backupPath is replaced instead of written through, and the
+// backup gets the original file's permissions rather than those of an older backup.
+func writeBackup(backupPath string, data []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(backupPath), "."+filepath.Base(backupPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	tempPath := temp.Name()
+	cleanup := func(err error) error {
+		temp.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if _, err := temp.Write(data); err != nil {
+		return cleanup(fmt.Errorf("failed to write temporary file: %v", err))
+	}
+	if err := temp.Chmod(perm); err != nil {
+		return cleanup(fmt.Errorf("failed to set file mode: %v", err))
+	}
+	if err := temp.Close(); err != nil {
+		return cleanup(fmt.Errorf("failed to close temporary file: %v", err))
+	}
+	if err := os.Rename(tempPath, backupPath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to replace %s: %v", backupPath, err)
+	}
+	return nil
+}
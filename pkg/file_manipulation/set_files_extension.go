@@ -4,11 +4,9 @@ package file_manipulation
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"strings"
-
-	"github.com/Protheophage/GO/pkg/random_utilities"
 )
 
 // SetFilesExtension changes the extension of files matching specific criteria.
@@ -39,39 +37,57 @@ import (
 //
 // ```
 func SetFilesExtension(filesToFind, newExtension string, searchAllDrives bool, checkThisDisk string) error {
-	if checkThisDisk == "" {
-		if os.PathSeparator == '/' {
-			checkThisDisk = "/"
-		} else {
-			checkThisDisk = filepath.Join(os.Getenv("SystemDrive"), "")
-		}
-	}
-
-	var drives []string
-	if searchAllDrives {
-		drives = random_utilities.GetAllDrives()
-	} else {
-		drives = []string{checkThisDisk}
-	}
-
-	for _, drive := range drives {
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
 		fmt.Printf("Changing file extensions on: %s\n", drive)
-		err := filepath.Walk(drive, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // Skip errors
-			}
-			if match, _ := filepath.Match(filesToFind, info.Name()); match {
-				newPath := strings.TrimSuffix(path, filepath.Ext(path)) + newExtension
-				if err := os.Rename(path, newPath); err != nil {
-					fmt.Printf("Failed to rename file: %s. Error: %v\n", path, err)
-				}
-			}
-			return nil
-		})
-		if err != nil {
+		if _, err := setFilesExtension(NewOSFS(drive), osPathFunc(drive), filesToFind, newExtension); err != nil {
 			return fmt.Errorf("error processing drive %s: %v", drive, err)
 		}
 	}
 
 	return nil
 }
+
+// SetFilesExtensionFS is SetFilesExtension for a WritableFS, such as an OSFS or a MemFS.
+//
+// Description:
+// - Renames the matching entries in the whole of fsys.
+// - Failures are printed and the remaining files are still processed.
+//
+// Parameters:
+// - fsys (WritableFS): The file system to change.
+// - filesToFind (string): The pattern of files to find (e.g., "*.txt").
+// - newExtension (string): The new extension to apply (e.g., ".log").
+//
+// Returns:
+// - []string: The new names of the renamed files.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// fsys := NewMemFS()
+// fsys.WriteFile("logs/app.txt", []byte("started\n"), 0o644)
+// renamed, err := SetFilesExtensionFS(fsys, "*.txt", ".log")
+// ```
+func SetFilesExtensionFS(fsys WritableFS, filesToFind, newExtension string) ([]string, error) {
+	return setFilesExtension(fsys, nil, filesToFind, newExtension)
+}
+
+// setFilesExtension renames the entries in fsys whose name matches a pattern and returns their new paths, converted with toPath.
+func setFilesExtension(fsys WritableFS, toPath func(name string) string, filesToFind, newExtension string) ([]string, error) {
+	if toPath == nil {
+		toPath = func(name string) string { return name }
+	}
+	var renamed []string
+	err := walkFS(fsys, ".", toPath, WalkOptions{}, func(name, path string, info fs.FileInfo) error {
+		if match, _ := filepath.Match(filesToFind, info.Name()); match {
+			newName := strings.TrimSuffix(name, filepath.Ext(name)) + newExtension
+			if err := fsys.Rename(name, newName); err != nil {
+				fmt.Printf("Failed to rename file: %s. Error: %v\n", path, err)
+				return nil
+			}
+			renamed = append(renamed, toPath(newName))
+		}
+		return nil
+	})
+	return renamed, err
+}
@@ -0,0 +1,236 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Protheophage/GO/pkg/random_utilities"
+)
+
+// ErrShredHardLinked is returned by ShredFile for files with more than one hard link.
+// Overwriting such a file would destroy the data behind its other names, so it is left untouched.
+var ErrShredHardLinked = errors.New("file has multiple hard links")
+
+// ShredOptions controls how ShredFile overwrites a file before removing it.
+type ShredOptions struct {
+	Passes int    // Number of overwrite passes (default: 3)
+	Fill   string // "random" (default) or "zeros"
+}
+
+// ShredWarning explains why a secure overwrite could not be guaranteed for a file.
+type ShredWarning struct {
+	Path   string
+	Reason string
+}
+
+// ShredReport lists the outcome of RemoveFilesShredded.
+type ShredReport struct {
+	Shredded      []string       // Files that were overwritten and removed
+	NotGuaranteed []ShredWarning // Files whose data may survive the overwrite, or that were skipped
+	Failed        []string       // Files that could not be shredded, with the reason
+}
+
+// ShredFile securely overwrites a file and then removes it.
+//
+// Description:
+// - Overwrites the file contents in place for the configured number of passes with random data or zeros.
+// - Flushes each pass to disk with fsync, then truncates the file.
+// - Renames the file to a random name (via GetRandomString) so the original name is not left in the directory, then unlinks it.
+// - Refuses files with more than one hard link (ErrShredHardLinked), on Windows as well as on Unix.
+// - Returns a warning when the file lives on a copy-on-write filesystem, where old blocks may survive the overwrite.
+//
+// Parameters:
+// - path (string): The file to shred.
+// - options (ShredOptions): Number of passes and fill pattern.
+//
+// Returns:
+// - string: A reason the overwrite cannot be guaranteed, or "" if it can.
+// - error: An error if the file cannot be overwritten or removed.
+//
+// Example Usage:
+// ```go
+// warning, err := ShredFile("/tmp/credentials.csv", ShredOptions{Passes: 3, Fill: "random"})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else if warning != "" {
+//
+//	    fmt.Println("Shredded, but:", warning)
+//	}
+//
+// ```
+func ShredFile(path string, options ShredOptions) (string, error) {
+	if options.Passes <= 0 {
+		options.Passes = 3
+	}
+	if options.Fill == "" {
+		options.Fill = "random"
+	}
+	if options.Fill != "random" && options.Fill != "zeros" {
+		return "", fmt.Errorf("invalid fill option: %s", options.Fill)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to access file %s: %v", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", path)
+	}
+	var warning string
+	if fsType := copyOnWriteFilesystem(path); fsType != "" {
+		warning = fmt.Sprintf("file is on a copy-on-write filesystem (%s); previous data blocks may remain on disk", fsType)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return warning, fmt.Errorf("failed to open file %s for overwriting: %v", path, err)
+	}
+	// Checked on the open file, which also covers Windows where os.FileInfo has no link count.
+	links, err := getOpenFileLinks(file)
+	if err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to read the link count of %s: %v", path, err)
+	}
+	if links > 1 {
+		file.Close()
+		return "", fmt.Errorf("%s: %w (%d links)", path, ErrShredHardLinked, links)
+	}
+
+	buffer := make([]byte, 64*1024)
+	for pass := 0; pass < options.Passes; pass++ {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return warning, fmt.Errorf("failed to overwrite file %s: %v", path, err)
+		}
+		for remaining := info.Size(); remaining > 0; {
+			chunk := buffer
+			if remaining < int64(len(chunk)) {
+				chunk = chunk[:remaining]
+			}
+			if options.Fill == "random" {
+				rand.Read(chunk)
+			} else {
+				clear(chunk)
+			}
+			n, err := file.Write(chunk)
+			if err != nil {
+				file.Close()
+				return warning, fmt.Errorf("failed to overwrite file %s: %v", path, err)
+			}
+			remaining -= int64(n)
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return warning, fmt.Errorf("failed to flush file %s: %v", path, err)
+		}
+	}
+
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return warning, fmt.Errorf("failed to truncate file %s: %v", path, err)
+	}
+	file.Sync()
+	if err := file.Close(); err != nil {
+		return warning, fmt.Errorf("failed to close file %s: %v", path, err)
+	}
+
+	randomPath := filepath.Join(filepath.Dir(path), randomFileName(len(info.Name())))
+	if err := os.Rename(path, randomPath); err != nil {
+		return warning, fmt.Errorf("failed to rename file %s: %v", path, err)
+	}
+	if err := os.Remove(randomPath); err != nil {
+		return warning, fmt.Errorf("failed to remove file %s (renamed to %s): %v", path, randomPath, err)
+	}
+
+	return warning, nil
+}
+
+// RemoveFilesShredded securely deletes files matching specific criteria.
+//
+// Description:
+// - Searches for files matching a pattern and shreds each regular file with ShredFile.
+// - Hard-linked files are skipped and reported as not guaranteed.
+// - Files on copy-on-write filesystems are shredded and reported as not guaranteed.
+//
+// Parameters:
+// - filesToDelete (string): The pattern of files to shred (e.g., "*.csv").
+// - searchAllDrives (bool): Whether to search all drives or a specific directory.
+// - checkThisDisk (string): The specific directory or drive to search (ignored if searchAllDrives is true).
+// - options (ShredOptions): Number of passes and fill pattern.
+//
+// Returns:
+// - ShredReport: The shredded, not guaranteed and failed files.
+// - error: An error if the operation fails.
+//
+// Example Usage:
+// ```go
+// report, err := RemoveFilesShredded("*.pem", false, "/srv/leak", ShredOptions{Passes: 1, Fill: "zeros"})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Shredded:", report.Shredded)
+//	}
+//
+// ```
+func RemoveFilesShredded(filesToDelete string, searchAllDrives bool, checkThisDisk string, options ShredOptions) (ShredReport, error) {
+	var report ShredReport
+
+	for _, drive := range getSearchRoots(searchAllDrives, checkThisDisk) {
+		fmt.Printf("Shredding files in: %s\n", drive)
+		var matched []string
+		err := WalkFiles(drive, WalkOptions{}, func(path string, info os.FileInfo) error {
+			if match, _ := filepath.Match(filesToDelete, info.Name()); match && info.Mode().IsRegular() {
+				matched = append(matched, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return report, fmt.Errorf("error processing drive %s: %v", drive, err)
+		}
+
+		for _, path := range matched {
+			warning, err := ShredFile(path, options)
+			switch {
+			case errors.Is(err, ErrShredHardLinked):
+				report.NotGuaranteed = append(report.NotGuaranteed, ShredWarning{Path: path, Reason: "skipped: " + err.Error()})
+			case err != nil:
+				report.Failed = append(report.Failed, err.Error())
+			default:
+				report.Shredded = append(report.Shredded, path)
+				if warning != "" {
+					report.NotGuaranteed = append(report.NotGuaranteed, ShredWarning{Path: path, Reason: warning})
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// randomFileName builds a random name of the given length (at least 8) from the letters and
+// digits returned by GetRandomString, skipping characters that are not valid in file names.
+func randomFileName(length int) string {
+	if length < 8 {
+		length = 8
+	}
+
+	var name strings.Builder
+	for name.Len() < length {
+		for _, char := range random_utilities.GetRandomString(length) {
+			if name.Len() < length && (char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9') {
+				name.WriteRune(char)
+			}
+		}
+	}
+	return name.String()
+}
@@ -0,0 +1,264 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SyncOptions controls how SyncDirectories compares and copies files.
+type SyncOptions struct {
+	CompareByHash    bool     // Compare file contents by SHA-256 instead of size and modification time
+	DeleteExtraneous bool     // Delete destination entries that do not exist in the source
+	DryRun           bool     // Report the planned changes without touching the destination
+	Include          []string // Only sync files matching one of these patterns
+	Exclude          []string // Skip entries matching one of these patterns
+}
+
+// SyncReport lists the changes made (or planned, in a dry run) by SyncDirectories.
+type SyncReport struct {
+	Added     []string // Destination paths copied because they did not exist
+	Updated   []string // Destination paths overwritten because they differed from the source
+	Deleted   []string // Destination paths removed because they do not exist in the source
+	Unchanged int      // Number of files that were already up to date
+	Failed    []string // Paths that could not be synced, with the reason
+}
+
+// partialSuffix marks an in-progress copy so an interrupted sync can resume it.
+const partialSuffix = ".fmsync-partial"
+
+// SyncDirectories mirrors a source directory into a destination directory (one-way).
+//
+// Description:
+// - Walks the source tree and copies files that are missing or changed in the destination.
+// - Files are compared by size and modification time, or by SHA-256 hash when CompareByHash is set.
+// - Copies are written to a partial file and renamed into place, preserving the source modification time.
+// - An interrupted copy is resumed from its partial file on the next run if the source has not changed.
+// - Optionally deletes destination entries that do not exist in the source.
+// - Destination entries under a source directory that could not be read are never deleted, and the read error is reported as failed.
+// - Partial copies left by an interrupted sync are not deleted, so the next run can resume them.
+// - There is no FS variant: resuming partial copies and keeping modification times need writes that WritableFS does not offer.
+//
+// Parameters:
+// - sourceDir (string): The directory to copy from.
+// - destinationDir (string): The directory to copy to (created if missing).
+// - options (SyncOptions): Comparison, deletion, dry-run and pattern options.
+//
+// Returns:
+// - SyncReport: The added, updated and deleted paths.
+// - error: An error if the source cannot be read or the destination cannot be created.
+//
+// Example Usage:
+// ```go
+// report, err := SyncDirectories("/srv/staging", "/mnt/mirror", SyncOptions{DeleteExtraneous: true, DryRun: true})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Would add:", report.Added)
+//	}
+//
+// ```
+func SyncDirectories(sourceDir, destinationDir string, options SyncOptions) (SyncReport, error) {
+	var report SyncReport
+
+	sourceDir = filepath.Clean(sourceDir)
+	destinationDir = filepath.Clean(destinationDir)
+
+	sourceInfo, err := os.Stat(sourceDir)
+	if err != nil {
+		return report, fmt.Errorf("failed to access source directory %s: %v", sourceDir, err)
+	}
+	if !sourceInfo.IsDir() {
+		return report, fmt.Errorf("source %s is not a directory", sourceDir)
+	}
+	if !options.DryRun {
+		if err := os.MkdirAll(destinationDir, sourceInfo.Mode().Perm()); err != nil {
+			return report, fmt.Errorf("failed to create destination directory %s: %v", destinationDir, err)
+		}
+	}
+
+	// Source entries that could not be read must not make their destination copies look extraneous.
+	var unreadable []string
+	walkOptions := WalkOptions{Include: options.Include, Exclude: options.Exclude, OnError: func(path string, err error) {
+		relPath, _ := filepath.Rel(sourceDir, path)
+		unreadable = append(unreadable, filepath.Join(destinationDir, relPath))
+		report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", path, err))
+	}}
+	sourceEntries := map[string]bool{}
+
+	fmt.Printf("Syncing: %s to %s\n", sourceDir, destinationDir)
+	err = WalkFiles(sourceDir, walkOptions, func(path string, info os.FileInfo) error {
+		relPath, _ := filepath.Rel(sourceDir, path)
+		sourceEntries[relPath] = true
+		destPath := filepath.Join(destinationDir, relPath)
+
+		if info.IsDir() {
+			if !options.DryRun {
+				if err := os.MkdirAll(destPath, info.Mode().Perm()); err != nil {
+					report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", destPath, err))
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		destInfo, err := os.Stat(destPath)
+		exists := err == nil
+		if exists {
+			same, err := filesMatch(path, info, destPath, destInfo, options.CompareByHash)
+			if err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", destPath, err))
+				return nil
+			}
+			if same {
+				report.Unchanged++
+				return nil
+			}
+		}
+
+		if !options.DryRun {
+			if err := copyFileResumable(path, info, destPath); err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", destPath, err))
+				return nil
+			}
+		}
+		if exists {
+			report.Updated = append(report.Updated, destPath)
+		} else {
+			report.Added = append(report.Added, destPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("error syncing directory %s: %v", sourceDir, err)
+	}
+
+	if options.DeleteExtraneous {
+		var extraneous []string
+		WalkFiles(destinationDir, WalkOptions{Exclude: options.Exclude}, func(path string, info os.FileInfo) error {
+			relPath, _ := filepath.Rel(destinationDir, path)
+			if sourceEntries[relPath] || isUnderAny(path, unreadable) || strings.HasSuffix(info.Name(), partialSuffix) {
+				return nil
+			}
+			if !info.IsDir() && len(options.Include) > 0 && !matchesAnyPattern(options.Include, info.Name(), filepath.ToSlash(relPath)) {
+				return nil
+			}
+			extraneous = append(extraneous, path)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+
+		// Remove the deepest paths first so directories are emptied before they are removed.
+		sort.Slice(extraneous, func(i, j int) bool { return len(extraneous[i]) > len(extraneous[j]) })
+		for _, path := range extraneous {
+			if !options.DryRun {
+				if err := os.RemoveAll(path); err != nil {
+					report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", path, err))
+					continue
+				}
+			}
+			report.Deleted = append(report.Deleted, path)
+		}
+		sort.Strings(report.Deleted)
+	}
+
+	return report, nil
+}
+
+// filesMatch reports whether the destination file is already up to date with the source file.
+func filesMatch(sourcePath string, sourceInfo os.FileInfo, destPath string, destInfo os.FileInfo, compareByHash bool) (bool, error) {
+	if !destInfo.Mode().IsRegular() || sourceInfo.Size() != destInfo.Size() {
+		return false, nil
+	}
+	if !compareByHash {
+		return sourceInfo.ModTime().Equal(destInfo.ModTime()), nil
+	}
+
+	sourceHash, err := GetFileHash(sourcePath, "sha256")
+	if err != nil {
+		return false, err
+	}
+	destHash, err := GetFileHash(destPath, "sha256")
+	if err != nil {
+		return false, err
+	}
+	return sourceHash == destHash, nil
+}
+
+// copyFileResumable copies a file through a partial file named after the source size and
+// modification time, so an interrupted copy of an unchanged source can be continued.
+func copyFileResumable(sourcePath string, sourceInfo os.FileInfo, destPath string) error {
+	dir, name := filepath.Split(destPath)
+	stamp := fmt.Sprintf("%x-%x", sourceInfo.Size(), sourceInfo.ModTime().UnixNano())
+	partialPath := filepath.Join(dir, "."+name+"."+stamp+partialSuffix)
+
+	// Discard partial copies left behind by an older version of the source.
+	if entries, err := os.ReadDir(filepath.Clean(dir)); err == nil {
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), "."+name+".") && strings.HasSuffix(entry.Name(), partialSuffix) {
+				if path := filepath.Join(dir, entry.Name()); path != partialPath {
+					os.Remove(path)
+				}
+			}
+		}
+	}
+
+	var offset int64
+	if info, err := os.Stat(partialPath); err == nil && info.Size() <= sourceInfo.Size() {
+		offset = info.Size()
+	}
+
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	partial, err := os.OpenFile(partialPath, os.O_CREATE|os.O_WRONLY, sourceInfo.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		fmt.Printf("Resuming copy of %s at byte %d\n", sourcePath, offset)
+	}
+	if _, err := source.Seek(offset, io.SeekStart); err != nil {
+		partial.Close()
+		return err
+	}
+	if _, err := partial.Seek(offset, io.SeekStart); err != nil {
+		partial.Close()
+		return err
+	}
+	if _, err := io.Copy(partial, throttleReader(source)); err != nil {
+		partial.Close()
+		return err
+	}
+	if err := partial.Truncate(sourceInfo.Size()); err != nil {
+		partial.Close()
+		return err
+	}
+	if err := partial.Sync(); err != nil {
+		partial.Close()
+		return err
+	}
+	if err := partial.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(partialPath, sourceInfo.ModTime(), sourceInfo.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(partialPath, destPath)
+}
@@ -0,0 +1,220 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text encodings recognized by DetectTextEncoding and EncodeString.
+const (
+	EncodingUTF8    = "UTF-8"
+	EncodingUTF16LE = "UTF-16LE"
+	EncodingUTF16BE = "UTF-16BE"
+	EncodingLatin1  = "Latin-1"
+)
+
+// DetectTextEncoding determines the text encoding of file contents.
+//
+// Description:
+// - Recognizes UTF-8, UTF-16LE and UTF-16BE byte order marks.
+// - Without a BOM, treats text whose odd (or even) bytes are mostly zero as UTF-16LE (or UTF-16BE).
+// - Otherwise returns UTF-8 for valid UTF-8 and falls back to Latin-1.
+//
+// Parameters:
+// - data ([]byte): The file contents, or at least their first few kilobytes.
+//
+// Returns:
+// - string: The encoding (EncodingUTF8, EncodingUTF16LE, EncodingUTF16BE or EncodingLatin1).
+// - int: The length of the byte order mark, or 0 if there is none.
+//
+// Example Usage:
+// ```go
+// data, _ := os.ReadFile("C:\\Windows\\Logs\\setup.log")
+// encoding, bomLength := DetectTextEncoding(data)
+// fmt.Println("Encoding:", encoding, "BOM bytes:", bomLength)
+// ```
+func DetectTextEncoding(data []byte) (string, int) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingUTF8, 3
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return EncodingUTF16LE, 2
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return EncodingUTF16BE, 2
+	}
+
+	sample := data
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	if pairs := len(sample) / 2; pairs > 0 {
+		var evenZeros, oddZeros int
+		for i := 0; i+1 < len(sample); i += 2 {
+			if sample[i] == 0 {
+				evenZeros++
+			}
+			if sample[i+1] == 0 {
+				oddZeros++
+			}
+		}
+		// ASCII-range text in UTF-16 has a zero in every other byte.
+		switch {
+		case oddZeros*10 >= pairs*4 && evenZeros*10 < pairs:
+			return EncodingUTF16LE, 0
+		case evenZeros*10 >= pairs*4 && oddZeros*10 < pairs:
+			return EncodingUTF16BE, 0
+		}
+	}
+
+	// Ignore a rune cut off at the end of the sample.
+	for trim := 0; trim < utf8.UTFMax && len(sample) > 0; trim++ {
+		if utf8.Valid(sample[:len(sample)-trim]) {
+			return EncodingUTF8, 0
+		}
+	}
+	return EncodingLatin1, 0
+}
+
+// DecodeText converts file contents in the given encoding to a string.
+//
+// Description:
+// - Also returns the byte offset in data of every character of the string, so matches can be reported in both bytes and characters.
+// - Invalid UTF-8 sequences decode to U+FFFD; a trailing odd byte of UTF-16 data is ignored.
+//
+// Parameters:
+// - data ([]byte): The contents to decode, after any byte order mark.
+// - encoding (string): The encoding returned by DetectTextEncoding (names such as "utf-16le" are also accepted).
+//
+// Returns:
+// - string: The decoded text.
+// - []int: The byte offset in data of each character (rune) of the text.
+// - error: An error if the encoding is not supported.
+//
+// Example Usage:
+// ```go
+// text, offsets, err := DecodeText(data[bomLength:], encoding)
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println(text, len(offsets))
+//	}
+//
+// ```
+func DecodeText(data []byte, encoding string) (string, []int, error) {
+	var text strings.Builder
+	var offsets []int
+
+	switch canonicalEncoding(encoding) {
+	case EncodingUTF8:
+		for offset := 0; offset < len(data); {
+			r, size := utf8.DecodeRune(data[offset:])
+			text.WriteRune(r)
+			offsets = append(offsets, offset)
+			offset += size
+		}
+	case EncodingUTF16LE, EncodingUTF16BE:
+		bigEndian := canonicalEncoding(encoding) == EncodingUTF16BE
+		unit := func(offset int) uint16 {
+			if bigEndian {
+				return uint16(data[offset])<<8 | uint16(data[offset+1])
+			}
+			return uint16(data[offset+1])<<8 | uint16(data[offset])
+		}
+		for offset := 0; offset+1 < len(data); {
+			r, size := rune(unit(offset)), 2
+			if utf16.IsSurrogate(r) && offset+3 < len(data) {
+				if pair := utf16.DecodeRune(r, rune(unit(offset+2))); pair != utf8.RuneError {
+					r, size = pair, 4
+				}
+			}
+			if utf16.IsSurrogate(r) {
+				r = utf8.RuneError
+			}
+			text.WriteRune(r)
+			offsets = append(offsets, offset)
+			offset += size
+		}
+	case EncodingLatin1:
+		for offset, b := range data {
+			text.WriteRune(rune(b))
+			offsets = append(offsets, offset)
+		}
+	default:
+		return "", nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+
+	return text.String(), offsets, nil
+}
+
+// EncodeString converts a string to its bytes in the given encoding, without a byte order mark.
+//
+// Description:
+// - Latin-1 cannot represent characters above U+00FF; an error is returned for such strings.
+//
+// Parameters:
+// - value (string): The string to encode.
+// - encoding (string): EncodingUTF8, EncodingUTF16LE, EncodingUTF16BE or EncodingLatin1.
+//
+// Returns:
+// - []byte: The encoded bytes.
+// - error: An error if the encoding is not supported or cannot represent the string.
+//
+// Example Usage:
+// ```go
+// needle, err := EncodeString("password", EncodingUTF16LE)
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func EncodeString(value, encoding string) ([]byte, error) {
+	switch canonicalEncoding(encoding) {
+	case EncodingUTF8:
+		return []byte(value), nil
+	case EncodingUTF16LE, EncodingUTF16BE:
+		bigEndian := canonicalEncoding(encoding) == EncodingUTF16BE
+		var encoded []byte
+		for _, unit := range utf16.Encode([]rune(value)) {
+			if bigEndian {
+				encoded = append(encoded, byte(unit>>8), byte(unit))
+			} else {
+				encoded = append(encoded, byte(unit), byte(unit>>8))
+			}
+		}
+		return encoded, nil
+	case EncodingLatin1:
+		var encoded []byte
+		for _, r := range value {
+			if r > 0xFF {
+				return nil, fmt.Errorf("%q cannot be encoded as Latin-1", value)
+			}
+			encoded = append(encoded, byte(r))
+		}
+		return encoded, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+}
+
+// canonicalEncoding maps encoding names such as "utf16le" or "ISO-8859-1" to the Encoding constants,
+// returning "" for unsupported encodings.
+func canonicalEncoding(name string) string {
+	switch strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name)) {
+	case "utf8":
+		return EncodingUTF8
+	case "utf16le":
+		return EncodingUTF16LE
+	case "utf16be":
+		return EncodingUTF16BE
+	case "latin1", "iso88591":
+		return EncodingLatin1
+	}
+	return ""
+}
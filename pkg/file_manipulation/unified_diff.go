@@ -0,0 +1,180 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffLine is one line of a line-by-line diff: ' ' (unchanged), '-' (removed) or '+' (added).
+// The text keeps its line ending, so lines that differ only in "\r\n" versus "\n", or in a missing final newline, differ.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff renders the differences between two versions of a file as a unified diff.
+//
+// Description:
+// - Compares the texts line by line and prints hunks with three lines of context, like `diff -u`.
+// - Returns "" when the texts are identical.
+// - Line endings are compared too: a line that only changes from "\r\n" to "\n" is shown as removed and added.
+// - A last line without a newline is followed by "\ No newline at end of file", as in `diff -u`.
+// - Very large changed regions are shown as a full replacement instead of a minimal diff.
+//
+// Parameters:
+// - path (string): The file name to show in the diff header.
+// - oldText (string): The original contents.
+// - newText (string): The new contents.
+//
+// Returns:
+// - string: The unified diff.
+//
+// Example Usage:
+// ```go
+// fmt.Print(UnifiedDiff("app.conf", "port=80\n", "port=8080\n"))
+// ```
+func UnifiedDiff(path, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	const context = 3
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", path, path)
+
+	for start := 0; start < len(lines); {
+		// Find the next change and the end of its hunk, merging changes separated by little context.
+		first := start
+		for first < len(lines) && lines[first].kind == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last := first
+		for next := first; next < len(lines); next++ {
+			if lines[next].kind != ' ' {
+				last = next
+			} else if next-last > 2*context {
+				break
+			}
+		}
+		hunkStart := max(first-context, start)
+		hunkEnd := min(last+context+1, len(lines))
+
+		// Line numbers of the hunk's first line in the old and new text.
+		oldLine, newLine := 1, 1
+		for _, line := range lines[:hunkStart] {
+			if line.kind != '+' {
+				oldLine++
+			}
+			if line.kind != '-' {
+				newLine++
+			}
+		}
+		var oldCount, newCount int
+		for _, line := range lines[hunkStart:hunkEnd] {
+			if line.kind != '+' {
+				oldCount++
+			}
+			if line.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		fmt.Fprintf(&diff, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, line := range lines[hunkStart:hunkEnd] {
+			diff.WriteByte(line.kind)
+			diff.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				diff.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = hunkEnd
+	}
+
+	return diff.String()
+}
+
+// splitLines splits text into lines, keeping their line endings. Only the last line can lack a "\n".
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a line diff using the longest common subsequence of the changed middle section.
+func diffLines(a, b []string) []diffLine {
+	var prefix, suffix int
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []diffLine
+	for _, text := range a[:prefix] {
+		lines = append(lines, diffLine{' ', text})
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(middleA)*len(middleB) > 4*1024*1024 {
+		// Too large for the quadratic LCS table; show the region as replaced.
+		for _, text := range middleA {
+			lines = append(lines, diffLine{'-', text})
+		}
+		for _, text := range middleB {
+			lines = append(lines, diffLine{'+', text})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of middleA[i:] and middleB[j:].
+		lcs := make([][]int, len(middleA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(middleB)+1)
+		}
+		for i := len(middleA) - 1; i >= 0; i-- {
+			for j := len(middleB) - 1; j >= 0; j-- {
+				if middleA[i] == middleB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(middleA) || j < len(middleB) {
+			switch {
+			case i < len(middleA) && j < len(middleB) && middleA[i] == middleB[j]:
+				lines = append(lines, diffLine{' ', middleA[i]})
+				i++
+				j++
+			case j == len(middleB) || (i < len(middleA) && lcs[i+1][j] >= lcs[i][j+1]):
+				lines = append(lines, diffLine{'-', middleA[i]})
+				i++
+			default:
+				lines = append(lines, diffLine{'+', middleB[j]})
+				j++
+			}
+		}
+	}
+
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	return lines
+}
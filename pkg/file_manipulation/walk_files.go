@@ -0,0 +1,172 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Protheophage/GO/pkg/random_utilities"
+)
+
+// WalkOptions controls which entries WalkFiles reports.
+type WalkOptions struct {
+	Include []string                     // Patterns an entry must match to be reported (empty matches everything)
+	Exclude []string                     // Patterns of entries to skip; excluded directories are not descended into
+	OnError func(path string, err error) // Called for each entry that cannot be read, instead of skipping it silently
+}
+
+// WalkFiles walks a directory tree and reports every entry below the root.
+//
+// Description:
+// - Walks the tree rooted at root and calls walkFn for each entry except the root itself. Symbolic links below the root are not followed.
+// - Include and exclude patterns are matched against both the entry name and its slash-separated path relative to root.
+// - Include patterns only filter files; directories are always descended into unless excluded.
+// - Entries that cannot be read are skipped, after calling options.OnError if it is set.
+// - Each file waits for the files-per-second limit set with SetIOLimits before it is reported.
+//
+// Parameters:
+// - root (string): The directory to walk.
+// - options (WalkOptions): Include and exclude patterns.
+// - walkFn (func(path string, info os.FileInfo) error): Called for each entry. Returning filepath.SkipDir skips a directory.
+//
+// Returns:
+// - error: An error if the root cannot be walked or walkFn returns an error.
+//
+// Example Usage:
+// ```go
+//
+//	err := WalkFiles("/var/log", WalkOptions{Include: []string{"*.log"}}, func(path string, info os.FileInfo) error {
+//	    fmt.Println(path)
+//	    return nil
+//	})
+//
+// ```
+func WalkFiles(root string, options WalkOptions, walkFn func(path string, info os.FileInfo) error) error {
+	return walkFS(os.DirFS(root), ".", osPathFunc(root), options, func(_, path string, info fs.FileInfo) error {
+		return walkFn(path, info)
+	})
+}
+
+// WalkFilesFS is WalkFiles for any fs.FS, such as an archive, a mounted image or a MemFS.
+//
+// Description:
+// - Walks the tree rooted at root in fsys with the same filtering and throttling as WalkFiles.
+// - Paths passed to walkFn are fsys names (slash-separated, e.g., "logs/app.log").
+//
+// Parameters:
+// - fsys (fs.FS): The file system to walk.
+// - root (string): The directory to walk within fsys ("." for the whole file system).
+// - options (WalkOptions): Include and exclude patterns.
+// - walkFn (func(path string, info fs.FileInfo) error): Called for each entry. Returning fs.SkipDir skips a directory.
+//
+// Returns:
+// - error: An error if walkFn returns an error.
+//
+// Example Usage:
+// ```go
+//
+//	err := WalkFilesFS(os.DirFS("/mnt/evidence"), "var/log", WalkOptions{Include: []string{"*.log"}}, func(path string, info fs.FileInfo) error {
+//	    fmt.Println(path, info.Size())
+//	    return nil
+//	})
+//
+// ```
+func WalkFilesFS(fsys fs.FS, root string, options WalkOptions, walkFn func(path string, info fs.FileInfo) error) error {
+	return walkFS(fsys, root, nil, options, func(name, _ string, info fs.FileInfo) error {
+		return walkFn(name, info)
+	})
+}
+
+// walkFS walks root in fsys and calls walkFn with each entry's fsys name and the path to report for it.
+// toPath converts names to reported paths; nil reports the names unchanged.
+func walkFS(fsys fs.FS, root string, toPath func(name string) string, options WalkOptions, walkFn func(name, path string, info fs.FileInfo) error) error {
+	reportError := func(name string, err error) {
+		if options.OnError == nil {
+			return
+		}
+		if toPath != nil {
+			name = toPath(name)
+		}
+		options.OnError(name, err)
+	}
+	return fs.WalkDir(fsys, root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			reportError(name, err)
+			return nil // Skip errors
+		}
+		if name == root {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			reportError(name, err)
+			return nil
+		}
+
+		relPath := name
+		if root != "." {
+			relPath = strings.TrimPrefix(name, root+"/")
+		}
+
+		if matchesAnyPattern(options.Exclude, info.Name(), relPath) {
+			if info.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && len(options.Include) > 0 && !matchesAnyPattern(options.Include, info.Name(), relPath) {
+			return nil
+		}
+
+		if !info.IsDir() {
+			waitForFile()
+		}
+		path := name
+		if toPath != nil {
+			path = toPath(name)
+		}
+		return walkFn(name, path, info)
+	})
+}
+
+// osPathFunc returns a function converting names in os.DirFS(root) back to paths on the local disk.
+func osPathFunc(root string) func(name string) string {
+	return func(name string) string {
+		return filepath.Join(root, filepath.FromSlash(name))
+	}
+}
+
+// matchesAnyPattern reports whether the name or relative path matches one of the patterns.
+func matchesAnyPattern(patterns []string, name, relPath string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if match, _ := filepath.Match(pattern, name); match {
+			return true
+		}
+		if match, _ := filepath.Match(filepath.ToSlash(pattern), relPath); match {
+			return true
+		}
+	}
+	return false
+}
+
+// getSearchRoots returns the directories to search, using the same defaults as the other file_manipulation functions.
+func getSearchRoots(searchAllDrives bool, checkThisDisk string) []string {
+	if searchAllDrives {
+		return random_utilities.GetAllDrives()
+	}
+	if checkThisDisk == "" {
+		if os.PathSeparator == '/' {
+			checkThisDisk = "/"
+		} else {
+			checkThisDisk = filepath.Join(os.Getenv("SystemDrive"), "")
+		}
+	}
+	return []string{checkThisDisk}
+}
@@ -0,0 +1,115 @@
+// This module is cross-platform (Windows and Linux).
+
+package file_manipulation
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WritableFS is a file system that the mutating file_manipulation functions can change.
+//
+// Names follow the io/fs conventions: slash-separated, relative to the root of the file system, without "." or ".." elements.
+// OSFS implements it on a directory of the local disk and MemFS implements it in memory.
+// Every search and read-only scan has an ...FS variant that takes an fs.FS. The exceptions work on the local disk only:
+// SyncDirectories and FindBrokenSymlinks need appends, timestamps or symbolic links; ShredFile and RemoveFilesShredded
+// overwrite disk blocks in place; AuditPermissions and WriteBodyfile read owners, inodes and access times; and the file
+// index stores local paths so that UpdateFileIndex can rescan them later.
+type WritableFS interface {
+	fs.FS
+	Remove(name string) error                                   // Removes a file or an empty directory
+	Rename(oldName, newName string) error                       // Renames (moves) a file or directory
+	WriteFile(name string, data []byte, perm fs.FileMode) error // Creates or truncates a file
+	MkdirAll(name string, perm fs.FileMode) error               // Creates a directory and any missing parents
+}
+
+// OSFS is a WritableFS rooted at a directory of the local disk.
+type OSFS struct {
+	fs.FS
+	root string
+}
+
+// NewOSFS returns a WritableFS for the directory tree rooted at root.
+//
+// Description:
+// - Reads go through os.DirFS(root), so names cannot escape the root with ".." elements.
+// - Writes resolve names against the same root and are rejected if the name is not a valid io/fs path.
+//
+// Parameters:
+// - root (string): The directory to expose (e.g., "/var/log" or "C:\\Logs").
+//
+// Returns:
+// - *OSFS: The file system.
+//
+// Example Usage:
+// ```go
+// fsys := NewOSFS("/srv/app")
+// removed, err := RemoveFilesFS(fsys, "*.tmp")
+// ```
+func NewOSFS(root string) *OSFS {
+	return &OSFS{FS: os.DirFS(root), root: root}
+}
+
+// Stat returns the FileInfo of the named file, following symbolic links.
+func (o *OSFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(o.FS, name)
+}
+
+// ReadFile reads the named file.
+func (o *OSFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(o.FS, name)
+}
+
+// ReadDir reads the named directory, sorted by file name.
+func (o *OSFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(o.FS, name)
+}
+
+// Remove removes the named file or empty directory.
+func (o *OSFS) Remove(name string) error {
+	path, err := o.osPath("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Rename renames (moves) oldName to newName.
+func (o *OSFS) Rename(oldName, newName string) error {
+	oldPath, err := o.osPath("rename", oldName)
+	if err != nil {
+		return err
+	}
+	newPath, err := o.osPath("rename", newName)
+	if err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// WriteFile writes data to the named file, creating it with perm if necessary.
+func (o *OSFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	path, err := o.osPath("open", name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// MkdirAll creates the named directory and any missing parents.
+func (o *OSFS) MkdirAll(name string, perm fs.FileMode) error {
+	path, err := o.osPath("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(path, perm)
+}
+
+// osPath converts a file system name to a path on the local disk.
+func (o *OSFS) osPath(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(o.root, filepath.FromSlash(name)), nil
+}
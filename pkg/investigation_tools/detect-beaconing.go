@@ -0,0 +1,340 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BeaconOptions configures a BeaconAnalyzer. Zero values use the defaults.
+type BeaconOptions struct {
+	MinConnections int     // Connections a (process, remote) pair needs before it is scored (default: 4, at least 3)
+	MinScore       float64 // Pairs scoring below this are left out of the results (default: 0, all scored pairs)
+}
+
+// BeaconCandidate summarizes the connections of one process to one remote endpoint.
+type BeaconCandidate struct {
+	ProcessName   string        `json:"process_name"`
+	ProcessIds    []string      `json:"process_ids"` // Every PID seen for the process name; a beacon started by a scheduler changes PID
+	RemoteAddress string        `json:"remote_address"`
+	RemotePort    string        `json:"remote_port"`
+	Connections   int           `json:"connections"`
+	FirstSeen     time.Time     `json:"first_seen"`
+	LastSeen      time.Time     `json:"last_seen"`
+	MeanInterval  time.Duration `json:"mean_interval"`           // Mean time between connection starts
+	Jitter        time.Duration `json:"jitter"`                  // Standard deviation of the time between connection starts
+	JitterRatio   float64       `json:"jitter_ratio"`            // Jitter divided by the mean interval; near 0 for a fixed-period beacon
+	MeanDuration  time.Duration `json:"mean_duration,omitempty"` // Mean time the closed connections were open
+	BytesSent     int64         `json:"bytes_sent,omitempty"`    // Totals, when the capture records bytes
+	BytesReceived int64         `json:"bytes_received,omitempty"`
+	Score         float64       `json:"score"` // 0 to 1; higher is more beacon-like
+}
+
+// beaconSample is one connection of a (process, remote) pair.
+type beaconSample struct {
+	start    time.Time
+	pid      string
+	duration time.Duration // Zero until the connection is seen closing
+	closed   bool
+	bytes    int64
+}
+
+// beaconPair collects the connections of one (process, remote) pair.
+type beaconPair struct {
+	processName   string
+	remoteAddress string
+	remotePort    string
+	samples       map[string]*beaconSample // By connection identity and start time
+	bytesSent     int64
+	bytesReceived int64
+	bytesRecorded bool
+}
+
+// BeaconAnalyzer scores (process, remote) pairs for beacon-like regularity from connection events.
+type BeaconAnalyzer struct {
+	mu      sync.Mutex
+	options BeaconOptions
+	pairs   map[string]*beaconPair
+}
+
+// NewBeaconAnalyzer creates an analyzer for connection events.
+//
+// Description:
+// - Feed it events with Observe, either live from MonitorNetworkConnections or from a capture with ReadConnectionEventsNDJSON.
+// - Connections are grouped by process name and remote address and port; the PID is not part of the key.
+// - Each connection counts once, however many events mention it; connections already open when the monitor started are ignored since their start time is unknown.
+// - TimeWait events (see MonitorOptions.CountTimeWait) count as connections starting when they were seen, without a duration.
+// - Results can be read at any time, including while events are still being observed.
+//
+// Parameters:
+// - options (BeaconOptions): Minimum connections per pair and minimum score to report.
+//
+// Returns:
+// - *BeaconAnalyzer: The analyzer.
+//
+// Example Usage:
+// ```go
+// analyzer := NewBeaconAnalyzer(BeaconOptions{MinConnections: 5})
+// err := MonitorNetworkConnections(ctx, MonitorOptions{Interval: time.Second}, analyzer.Observe)
+//
+//	for _, candidate := range analyzer.Results() {
+//	    fmt.Printf("%.2f %s -> %s:%s every %s\n", candidate.Score, candidate.ProcessName, candidate.RemoteAddress, candidate.RemotePort, candidate.MeanInterval)
+//	}
+//
+// ```
+func NewBeaconAnalyzer(options BeaconOptions) *BeaconAnalyzer {
+	if options.MinConnections <= 0 {
+		options.MinConnections = 4
+	}
+	options.MinConnections = max(options.MinConnections, 3) // Jitter needs at least two intervals
+	return &BeaconAnalyzer{options: options, pairs: map[string]*beaconPair{}}
+}
+
+// Observe records a connection event. It has the signature of a MonitorNetworkConnections event handler.
+func (a *BeaconAnalyzer) Observe(event ConnectionEvent) {
+	connection := event.Connection
+	if event.Preexisting || connection.RemotePort == "" || event.FirstSeen.IsZero() {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	pairKey := connection.ProcessName + "|" + connection.RemoteAddress + "|" + connection.RemotePort
+	pair, ok := a.pairs[pairKey]
+	if !ok {
+		pair = &beaconPair{
+			processName:   connection.ProcessName,
+			remoteAddress: connection.RemoteAddress,
+			remotePort:    connection.RemotePort,
+			samples:       map[string]*beaconSample{},
+		}
+		a.pairs[pairKey] = pair
+	}
+
+	sampleKey := connectionKey(connection) + "|" + event.FirstSeen.UTC().Format(time.RFC3339Nano)
+	sample, ok := pair.samples[sampleKey]
+	if !ok {
+		sample = &beaconSample{start: event.FirstSeen, pid: connection.ProcessId}
+		pair.samples[sampleKey] = sample
+	}
+	if event.Event == "closed" && !event.TimeWait && !sample.closed {
+		sample.closed = true
+		sample.duration = event.Duration
+		if event.BytesSent > 0 || event.BytesReceived > 0 {
+			sample.bytes = event.BytesSent + event.BytesReceived
+			pair.bytesSent += event.BytesSent
+			pair.bytesReceived += event.BytesReceived
+			pair.bytesRecorded = true
+		}
+	}
+}
+
+// Results scores every pair with enough connections and ranks them.
+//
+// Description:
+// - The jitter ratio (standard deviation over mean of the time between connection starts) gives the regularity: 1 - ratio, at least 0.
+// - When connection durations are known, connections that are short relative to the interval score higher.
+// - When bytes are recorded, connections of a consistent size score higher.
+// - The score is the weighted mean of the available parts (regularity 0.6, shortness 0.2, size 0.2), damped for pairs with few intervals by n/(n+2).
+//
+// Parameters: None
+//
+// Returns:
+// - []BeaconCandidate: The scored pairs, highest score first.
+//
+// Example Usage:
+// ```go
+// candidates := analyzer.Results()
+// ```
+func (a *BeaconAnalyzer) Results() []BeaconCandidate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var candidates []BeaconCandidate
+	for _, pair := range a.pairs {
+		if len(pair.samples) < a.options.MinConnections {
+			continue
+		}
+		candidate := scoreBeaconPair(pair)
+		if candidate.Score < a.options.MinScore {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Connections > candidates[j].Connections
+	})
+	return candidates
+}
+
+// ReadConnectionEventsNDJSON reads connection events written by WriteConnectionEventsNDJSON.
+//
+// Description:
+// - Calls onEvent for each event in order, so large captures are not held in memory.
+// - Blank lines are skipped.
+//
+// Parameters:
+// - reader (io.Reader): The NDJSON capture.
+// - onEvent (func(ConnectionEvent)): Called for each event (e.g., a BeaconAnalyzer's Observe method).
+//
+// Returns:
+// - error: An error if the capture cannot be read or a line is not a valid event.
+//
+// Example Usage:
+// ```go
+// file, _ := os.Open("connections.ndjson")
+// defer file.Close()
+// analyzer := NewBeaconAnalyzer(BeaconOptions{})
+//
+//	if err := ReadConnectionEventsNDJSON(file, analyzer.Observe); err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func ReadConnectionEventsNDJSON(reader io.Reader, onEvent func(ConnectionEvent)) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event ConnectionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("invalid event on line %d: %v", line, err)
+		}
+		onEvent(event)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read events: %v", err)
+	}
+	return nil
+}
+
+// AnalyzeBeaconingNDJSON scores the connections in a saved NDJSON capture for beaconing.
+//
+// Description:
+// - Reads the capture with ReadConnectionEventsNDJSON into a BeaconAnalyzer and returns its results.
+//
+// Parameters:
+// - path (string): The capture file written by the connection monitor.
+// - options (BeaconOptions): Minimum connections per pair and minimum score to report.
+//
+// Returns:
+// - []BeaconCandidate: The scored pairs, highest score first.
+// - error: An error if the capture cannot be read.
+//
+// Example Usage:
+// ```go
+// candidates, err := AnalyzeBeaconingNDJSON("connections.ndjson", BeaconOptions{MinScore: 0.7})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func AnalyzeBeaconingNDJSON(path string, options BeaconOptions) ([]BeaconCandidate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture: %v", err)
+	}
+	defer file.Close()
+
+	analyzer := NewBeaconAnalyzer(options)
+	if err := ReadConnectionEventsNDJSON(file, analyzer.Observe); err != nil {
+		return nil, err
+	}
+	return analyzer.Results(), nil
+}
+
+// scoreBeaconPair computes the interval statistics and score of a pair.
+func scoreBeaconPair(pair *beaconPair) BeaconCandidate {
+	samples := make([]*beaconSample, 0, len(pair.samples))
+	for _, sample := range pair.samples {
+		samples = append(samples, sample)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].start.Before(samples[j].start) })
+
+	candidate := BeaconCandidate{
+		ProcessName:   pair.processName,
+		RemoteAddress: pair.remoteAddress,
+		RemotePort:    pair.remotePort,
+		Connections:   len(samples),
+		FirstSeen:     samples[0].start,
+		LastSeen:      samples[len(samples)-1].start,
+		BytesSent:     pair.bytesSent,
+		BytesReceived: pair.bytesReceived,
+	}
+
+	var intervals, durations, sizes []float64
+	for i, sample := range samples {
+		if !slices.Contains(candidate.ProcessIds, sample.pid) && sample.pid != "" {
+			candidate.ProcessIds = append(candidate.ProcessIds, sample.pid)
+		}
+		if i > 0 {
+			intervals = append(intervals, sample.start.Sub(samples[i-1].start).Seconds())
+		}
+		if sample.closed {
+			durations = append(durations, sample.duration.Seconds())
+		}
+		if sample.bytes > 0 {
+			sizes = append(sizes, float64(sample.bytes))
+		}
+	}
+
+	meanInterval, jitter := meanAndStdDev(intervals)
+	candidate.MeanInterval = time.Duration(meanInterval * float64(time.Second))
+	candidate.Jitter = time.Duration(jitter * float64(time.Second))
+	if meanInterval <= 0 {
+		return candidate // Every connection started at once; not periodic
+	}
+	candidate.JitterRatio = jitter / meanInterval
+
+	score := 0.6 * math.Max(0, 1-candidate.JitterRatio)
+	weight := 0.6
+	if len(durations) > 0 {
+		meanDuration, _ := meanAndStdDev(durations)
+		candidate.MeanDuration = time.Duration(meanDuration * float64(time.Second))
+		score += 0.2 * math.Max(0, 1-meanDuration/meanInterval)
+		weight += 0.2
+	}
+	if pair.bytesRecorded && len(sizes) >= 2 {
+		meanSize, sizeDeviation := meanAndStdDev(sizes)
+		score += 0.2 * math.Max(0, 1-sizeDeviation/meanSize)
+		weight += 0.2
+	}
+	n := float64(len(intervals))
+	candidate.Score = score / weight * n / (n + 2)
+	return candidate
+}
+
+// meanAndStdDev returns the mean and population standard deviation of values.
+func meanAndStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)))
+}
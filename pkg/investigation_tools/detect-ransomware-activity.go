@@ -0,0 +1,343 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Protheophage/GO/pkg/file_manipulation"
+	"github.com/fsnotify/fsnotify"
+)
+
+// RansomwareThresholds configures DetectRansomwareActivity. Zero values use the defaults.
+type RansomwareThresholds struct {
+	Window           time.Duration // Sliding window the counts below are taken over (default: 30s)
+	RenameBurst      int           // Files renamed to the same new extension (default: 20)
+	WriteDeleteBurst int           // Existing files deleted right after writes in the same directory (default: 30)
+	NoteDirectories  int           // Directories where a ransom-note file name appears (default: 5)
+	HighEntropyFiles int           // Written files whose contents now look encrypted (default: 20)
+	MinEntropy       float64       // Entropy in bits per byte treated as encrypted (default: 7.5)
+	NotePatterns     []string      // Case-insensitive ransom-note name patterns (default: DefaultRansomNotePatterns)
+}
+
+// RansomwareAlert is raised when activity in the window crosses a threshold.
+type RansomwareAlert struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"` // "rename-burst", "write-delete-burst", "ransom-notes" or "entropy-jump"
+	Summary   string    `json:"summary"`
+	Count     int       `json:"count"`
+	Extension string    `json:"extension,omitempty"` // New extension, for rename bursts
+	Evidence  []string  `json:"evidence"`            // Paths that contributed to the alert
+}
+
+// DefaultRansomNotePatterns are file names commonly used for ransom notes.
+var DefaultRansomNotePatterns = []string{
+	"*decrypt*", "*recover*files*", "*restore*files*", "how_to_*", "*ransom*", "_readme.txt", "readme.hta", "readme_for_decrypt*",
+}
+
+// windowEntry is a path observed at a point in the sliding window.
+type windowEntry struct {
+	Time time.Time
+	Path string
+}
+
+// ransomwareDetector keeps the sliding-window state for DetectRansomwareActivity.
+type ransomwareDetector struct {
+	thresholds     RansomwareThresholds
+	renamed        []windowEntry            // Old names of recently renamed files
+	renamedTo      map[string][]windowEntry // New names, by the extension they were renamed to
+	created        map[string]time.Time     // Files created in the window
+	writesByDir    map[string]time.Time     // Last write in each directory
+	deletes        []windowEntry            // Existing files deleted after writes in the same directory
+	notes          map[string]windowEntry   // Ransom-note files, by directory
+	pendingEntropy map[string]time.Time     // Written files waiting to be measured, with their last write
+	highEntropy    []windowEntry            // Written files whose contents look encrypted
+}
+
+// DetectRansomwareActivity watches a directory tree and raises alerts on ransomware-like activity.
+//
+// Description:
+// - Keeps sliding-window statistics on the fsnotify event stream of the directory and its subdirectories.
+// - Raises "rename-burst" when many files are renamed to the same new extension (e.g., report.docx -> report.docx.locked).
+// - Raises "write-delete-burst" when many existing files are deleted right after writes in the same directory.
+// - Raises "ransom-notes" when files with ransom-note names appear in many directories.
+// - Raises "entropy-jump" when many written files now have near-maximal entropy and are not a naturally compressed format.
+// - Each alert carries the evidence paths; the counts for that alert restart after it fires.
+// - Runs until the context is cancelled.
+//
+// Parameters:
+// - ctx (context.Context): Stops the detector when cancelled.
+// - path (string): The directory path to monitor.
+// - thresholds (RansomwareThresholds): Window size and alert thresholds.
+// - onAlert (func(RansomwareAlert)): Called for each alert (use nil to log alerts as JSON).
+//
+// Returns:
+// - error: An error if the watcher fails to initialize.
+//
+// Example Usage:
+// ```go
+// ctx, cancel := context.WithCancel(context.Background())
+// defer cancel()
+//
+//	err := DetectRansomwareActivity(ctx, "/srv/share", RansomwareThresholds{RenameBurst: 10}, func(alert RansomwareAlert) {
+//	    fmt.Println(alert.Kind, alert.Summary, alert.Evidence)
+//	})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func DetectRansomwareActivity(ctx context.Context, path string, thresholds RansomwareThresholds, onAlert func(RansomwareAlert)) error {
+	if onAlert == nil {
+		onAlert = func(alert RansomwareAlert) {
+			output, _ := json.Marshal(alert)
+			log.Printf("ALERT %s", output)
+		}
+	}
+	detector := newRansomwareDetector(thresholds)
+	normalizedPath := normalizeWatchPath(path)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %v", err)
+	}
+	defer watcher.Close()
+
+	if err := addWatchTree(watcher, normalizedPath); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	log.Printf("Watching '%s' for ransomware activity.", normalizedPath)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			eventName := normalizeWatchPath(event.Name)
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				if info, err := os.Stat(eventName); err == nil && info.IsDir() {
+					addWatchTree(watcher, eventName)
+				}
+			}
+			for _, alert := range detector.observe(event.Op, eventName, time.Now()) {
+				onAlert(alert)
+			}
+		case now := <-ticker.C:
+			for _, alert := range detector.measureEntropy(now) {
+				onAlert(alert)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Error: %v", err)
+		}
+	}
+}
+
+// newRansomwareDetector fills in default thresholds and creates the detector state.
+func newRansomwareDetector(thresholds RansomwareThresholds) *ransomwareDetector {
+	if thresholds.Window <= 0 {
+		thresholds.Window = 30 * time.Second
+	}
+	if thresholds.RenameBurst <= 0 {
+		thresholds.RenameBurst = 20
+	}
+	if thresholds.WriteDeleteBurst <= 0 {
+		thresholds.WriteDeleteBurst = 30
+	}
+	if thresholds.NoteDirectories <= 0 {
+		thresholds.NoteDirectories = 5
+	}
+	if thresholds.HighEntropyFiles <= 0 {
+		thresholds.HighEntropyFiles = 20
+	}
+	if thresholds.MinEntropy <= 0 {
+		thresholds.MinEntropy = 7.5
+	}
+	if thresholds.NotePatterns == nil {
+		thresholds.NotePatterns = DefaultRansomNotePatterns
+	}
+
+	return &ransomwareDetector{
+		thresholds:     thresholds,
+		renamedTo:      map[string][]windowEntry{},
+		created:        map[string]time.Time{},
+		writesByDir:    map[string]time.Time{},
+		notes:          map[string]windowEntry{},
+		pendingEntropy: map[string]time.Time{},
+	}
+}
+
+// observe records one file event and returns any alerts it triggers.
+func (detector *ransomwareDetector) observe(op fsnotify.Op, path string, now time.Time) []RansomwareAlert {
+	detector.prune(now)
+	var alerts []RansomwareAlert
+	dir := filepath.Dir(path)
+
+	switch {
+	case op&fsnotify.Rename == fsnotify.Rename:
+		// fsnotify reports a rename as Rename on the old name followed by Create on the new one.
+		delete(detector.pendingEntropy, path)
+		detector.renamed = append(detector.renamed, windowEntry{Time: now, Path: path})
+
+	case op&fsnotify.Create == fsnotify.Create:
+		detector.created[path] = now
+		detector.pendingEntropy[path] = now
+		if extension := detector.renamedExtension(path); extension != "" {
+			detector.renamedTo[extension] = append(detector.renamedTo[extension], windowEntry{Time: now, Path: path})
+			if entries := detector.renamedTo[extension]; len(entries) >= detector.thresholds.RenameBurst {
+				alerts = append(alerts, newRansomwareAlert(now, "rename-burst", extension, entries,
+					fmt.Sprintf("%d files renamed to extension %s within %s", len(entries), extension, detector.thresholds.Window)))
+				delete(detector.renamedTo, extension)
+			}
+		}
+		if detector.isRansomNote(path) {
+			detector.notes[dir] = windowEntry{Time: now, Path: path}
+			if len(detector.notes) >= detector.thresholds.NoteDirectories {
+				var entries []windowEntry
+				for _, entry := range detector.notes {
+					entries = append(entries, entry)
+				}
+				alerts = append(alerts, newRansomwareAlert(now, "ransom-notes", "", entries,
+					fmt.Sprintf("ransom-note files appeared in %d directories within %s", len(entries), detector.thresholds.Window)))
+				detector.notes = map[string]windowEntry{}
+			}
+		}
+
+	case op&fsnotify.Write == fsnotify.Write:
+		detector.writesByDir[dir] = now
+		detector.pendingEntropy[path] = now
+
+	case op&fsnotify.Remove == fsnotify.Remove:
+		delete(detector.pendingEntropy, path)
+		_, createdInWindow := detector.created[path]
+		if _, written := detector.writesByDir[dir]; written && !createdInWindow {
+			detector.deletes = append(detector.deletes, windowEntry{Time: now, Path: path})
+			if len(detector.deletes) >= detector.thresholds.WriteDeleteBurst {
+				alerts = append(alerts, newRansomwareAlert(now, "write-delete-burst", "", detector.deletes,
+					fmt.Sprintf("%d existing files deleted after writes in the same directory within %s", len(detector.deletes), detector.thresholds.Window)))
+				detector.deletes = nil
+			}
+		}
+	}
+
+	return alerts
+}
+
+// measureEntropy checks written files that have not changed for a second and returns any alert.
+func (detector *ransomwareDetector) measureEntropy(now time.Time) []RansomwareAlert {
+	detector.prune(now)
+
+	for path, lastWrite := range detector.pendingEntropy {
+		if now.Sub(lastWrite) < time.Second {
+			continue
+		}
+		delete(detector.pendingEntropy, path)
+
+		fileType, err := file_manipulation.DetectFileType(path)
+		if err != nil || fileType.IsCompressed() {
+			continue
+		}
+		entropy, err := file_manipulation.GetFileEntropy(path, file_manipulation.EntropyOptions{SampleBlocks: 4})
+		if err == nil && entropy >= detector.thresholds.MinEntropy {
+			detector.highEntropy = append(detector.highEntropy, windowEntry{Time: now, Path: path})
+		}
+	}
+
+	if len(detector.highEntropy) < detector.thresholds.HighEntropyFiles {
+		return nil
+	}
+	alert := newRansomwareAlert(now, "entropy-jump", "", detector.highEntropy,
+		fmt.Sprintf("%d written files have entropy of at least %.2f within %s", len(detector.highEntropy), detector.thresholds.MinEntropy, detector.thresholds.Window))
+	detector.highEntropy = nil
+	return []RansomwareAlert{alert}
+}
+
+// renamedExtension returns the extension a newly created file was renamed to, or "" if the
+// file is not the new name of a recently renamed file.
+func (detector *ransomwareDetector) renamedExtension(path string) string {
+	for _, old := range detector.renamed {
+		if filepath.Dir(old.Path) != filepath.Dir(path) || old.Path == path {
+			continue
+		}
+		// Appended extension: report.docx -> report.docx.locked
+		if strings.HasPrefix(path, old.Path+".") {
+			return strings.ToLower(path[len(old.Path):])
+		}
+		// Replaced extension: report.docx -> report.crypt
+		oldStem := strings.TrimSuffix(old.Path, filepath.Ext(old.Path))
+		if extension := filepath.Ext(path); extension != "" && strings.TrimSuffix(path, extension) == oldStem {
+			return strings.ToLower(extension)
+		}
+	}
+	return ""
+}
+
+// isRansomNote reports whether a file name matches one of the ransom-note patterns.
+func (detector *ransomwareDetector) isRansomNote(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, pattern := range detector.thresholds.NotePatterns {
+		if match, _ := filepath.Match(strings.ToLower(pattern), name); match {
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops everything older than the window.
+func (detector *ransomwareDetector) prune(now time.Time) {
+	cutoff := now.Add(-detector.thresholds.Window)
+	keep := func(entries []windowEntry) []windowEntry {
+		index := sort.Search(len(entries), func(i int) bool { return entries[i].Time.After(cutoff) })
+		return entries[index:]
+	}
+
+	detector.renamed = keep(detector.renamed)
+	detector.deletes = keep(detector.deletes)
+	detector.highEntropy = keep(detector.highEntropy)
+	for extension, entries := range detector.renamedTo {
+		if entries = keep(entries); len(entries) == 0 {
+			delete(detector.renamedTo, extension)
+		} else {
+			detector.renamedTo[extension] = entries
+		}
+	}
+	for _, times := range []map[string]time.Time{detector.created, detector.writesByDir} {
+		for key, seen := range times {
+			if !seen.After(cutoff) {
+				delete(times, key)
+			}
+		}
+	}
+	for dir, entry := range detector.notes {
+		if !entry.Time.After(cutoff) {
+			delete(detector.notes, dir)
+		}
+	}
+}
+
+// newRansomwareAlert builds an alert with the paths of the window entries as evidence.
+func newRansomwareAlert(now time.Time, kind, extension string, entries []windowEntry, summary string) RansomwareAlert {
+	evidence := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		evidence = append(evidence, entry.Path)
+	}
+	sort.Strings(evidence)
+	return RansomwareAlert{Time: now, Kind: kind, Summary: summary, Count: len(entries), Extension: extension, Evidence: evidence}
+}
@@ -0,0 +1,239 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ProcessTriageOptions configures TriageProcesses. Zero values use the defaults.
+type ProcessTriageOptions struct {
+	TempDirectories []string // Directories that programs should not run from (default: /tmp, /var/tmp, /dev/shm)
+	RecoverDir      string   // Copy the executables of flagged processes here, named by SHA-256 (default: no copies)
+}
+
+// ProcessFinding is one reason a process was flagged.
+type ProcessFinding struct {
+	Reason string `json:"reason"` // "deleted-exe", "memfd-exe", "temp-dir-exe", "hidden-dir-exe", "name-mismatch", "fake-kernel-thread" or "hash-failed"
+	Detail string `json:"detail"`
+}
+
+// SuspiciousProcess is a process with at least one finding.
+type SuspiciousProcess struct {
+	Process       Process          `json:"process"`
+	Findings      []ProcessFinding `json:"findings"`
+	SHA256        string           `json:"sha256,omitempty"`         // Hash of the running executable, read through /proc/<pid>/exe
+	RecoveredPath string           `json:"recovered_path,omitempty"` // Copy of the executable, when RecoverDir is set
+}
+
+// DefaultTempDirectories are the world-writable directories TriageProcesses flags executables in.
+var DefaultTempDirectories = []string{"/tmp", "/var/tmp", "/dev/shm"}
+
+// kernelThreadPatterns match the names of common Linux kernel threads.
+var kernelThreadPatterns = []string{
+	"kthreadd", "kworker/*", "ksoftirqd/*", "migration/*", "rcu_*", "cpuhp/*", "watchdog/*", "watchdogd", "kswapd*",
+	"kcompactd*", "khugepaged", "kdevtmpfs", "kauditd", "kblockd", "kintegrityd", "oom_reaper", "writeback",
+	"jbd2/*", "irq/*", "scsi_*", "ext4-*", "idle_inject/*", "kthrotld", "ksmd", "netns", "md", "kstrp",
+}
+
+// interpreterPatterns match executables that run scripts or applets named on their command line.
+var interpreterPatterns = []string{"sh", "bash", "dash", "zsh", "ksh", "busybox", "python*", "perl*", "ruby*", "node", "php*"}
+
+// TriageProcesses looks for processes that use common Linux implant tricks.
+//
+// Description:
+// - "deleted-exe": the executable was deleted after the process started (also seen for daemons that outlived a package upgrade).
+// - "memfd-exe": the process runs from a memfd, an anonymous in-memory file, so there was never a binary on disk.
+// - "temp-dir-exe": the executable is in /tmp, /var/tmp, /dev/shm or another configured temporary directory.
+// - "hidden-dir-exe": a directory or file in the executable's path starts with ".".
+// - "name-mismatch": the process name is neither the executable's name nor the script it was started with.
+// - "fake-kernel-thread": the name looks like a kernel thread, but the process has a userland executable.
+// - Each flagged process's executable is hashed through /proc/<pid>/exe, which still works after the file is deleted.
+// - With RecoverDir, the executable is also copied there as <sha256>.bin, so deleted and memfd binaries can be analyzed.
+// - A "hash-failed" finding is added when the executable cannot be read, e.g., because the process exited.
+// - Without root, other users' processes cannot be checked, since their executable links are unreadable.
+//
+// Parameters:
+// - options (ProcessTriageOptions): Temporary directories and where to recover executables.
+//
+// Returns:
+// - []SuspiciousProcess: The flagged processes, sorted by PID.
+// - error: An error if the process list cannot be read or the platform is not Linux.
+//
+// Example Usage:
+// ```go
+// suspicious, err := TriageProcesses(ProcessTriageOptions{RecoverDir: "/root/evidence"})
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, result := range suspicious {
+//	        fmt.Println(result.Process.PID, result.Process.Name, result.Findings, result.SHA256)
+//	    }
+//	}
+//
+// ```
+func TriageProcesses(options ProcessTriageOptions) ([]SuspiciousProcess, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+	if len(options.TempDirectories) == 0 {
+		options.TempDirectories = DefaultTempDirectories
+	}
+	if options.RecoverDir != "" {
+		if err := os.MkdirAll(options.RecoverDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create recovery directory: %v", err)
+		}
+	}
+
+	processes, err := GetProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var suspicious []SuspiciousProcess
+	for _, process := range processes {
+		findings := checkProcess(process, options.TempDirectories)
+		if len(findings) == 0 {
+			continue
+		}
+		result := SuspiciousProcess{Process: process, Findings: findings}
+		result.SHA256, result.RecoveredPath, err = hashProcessExecutable(process.PID, options.RecoverDir)
+		if err != nil {
+			result.Findings = append(result.Findings, ProcessFinding{Reason: "hash-failed", Detail: err.Error()})
+		}
+		suspicious = append(suspicious, result)
+	}
+	return suspicious, nil
+}
+
+// checkProcess applies the triage heuristics to one process.
+func checkProcess(process Process, tempDirectories []string) []ProcessFinding {
+	if process.ExePath == "" {
+		return nil // Kernel thread, or not readable without root
+	}
+	var findings []ProcessFinding
+	exePath, deleted := strings.CutSuffix(process.ExePath, " (deleted)")
+
+	if strings.HasPrefix(exePath, "/memfd:") {
+		findings = append(findings, ProcessFinding{Reason: "memfd-exe", Detail: "running from an in-memory file: " + process.ExePath})
+	} else if deleted {
+		findings = append(findings, ProcessFinding{Reason: "deleted-exe", Detail: "executable was deleted: " + exePath})
+	}
+
+	for _, dir := range tempDirectories {
+		if strings.HasPrefix(exePath, strings.TrimSuffix(dir, "/")+"/") {
+			findings = append(findings, ProcessFinding{Reason: "temp-dir-exe", Detail: "running from " + dir + ": " + exePath})
+			break
+		}
+	}
+	for _, element := range strings.Split(exePath, "/") {
+		if strings.HasPrefix(element, ".") && element != "." && element != ".." {
+			findings = append(findings, ProcessFinding{Reason: "hidden-dir-exe", Detail: "running from a hidden path: " + exePath})
+			break
+		}
+	}
+
+	exeName := path.Base(exePath)
+	if !strings.HasPrefix(exePath, "/memfd:") && !processNameFromExe(process, exeName) {
+		findings = append(findings, ProcessFinding{
+			Reason: "name-mismatch",
+			Detail: fmt.Sprintf("process name %q does not match executable %q", process.Name, exeName),
+		})
+	}
+
+	if looksLikeKernelThread(process) {
+		findings = append(findings, ProcessFinding{
+			Reason: "fake-kernel-thread",
+			Detail: fmt.Sprintf("kernel thread name %q with userland executable %s (parent PID %d)", process.Name, process.ExePath, process.PPID),
+		})
+	}
+	return findings
+}
+
+// processNameFromExe reports whether the process name comes from its executable or, for an interpreter, from the script it runs.
+// Process names are truncated to 15 characters, and one name may be a prefix of the other (e.g., "python3" and "python3.12").
+func processNameFromExe(process Process, exeName string) bool {
+	candidates := []string{exeName}
+	if matchesAny(interpreterPatterns, func(pattern string) bool { return processNameMatches(pattern, exeName) }) {
+		// argv[0] is the applet of a multi-call binary; a script started through its shebang is argv[1], or argv[2] after an option.
+		// Other programs do not get this leeway, since an implant that renames itself usually rewrites its arguments too.
+		args := strings.Fields(process.Cmdline)
+		for i := 0; i < len(args) && i < 3; i++ {
+			candidates = append(candidates, path.Base(args[i]))
+		}
+	}
+	for _, candidate := range candidates {
+		if len(candidate) > 15 {
+			candidate = candidate[:15]
+		}
+		if candidate != "" && process.Name != "" && (strings.HasPrefix(candidate, process.Name) || strings.HasPrefix(process.Name, candidate)) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeKernelThread reports whether a process with an executable is named like a kernel thread.
+func looksLikeKernelThread(process Process) bool {
+	if strings.HasPrefix(process.Cmdline, "[") && strings.HasSuffix(process.Cmdline, "]") {
+		return true
+	}
+	for _, pattern := range kernelThreadPatterns {
+		if processNameMatches(pattern, process.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hashProcessExecutable hashes /proc/<pid>/exe with SHA-256 and, with a recovery directory, copies it there.
+func hashProcessExecutable(pid int, recoverDir string) (string, string, error) {
+	file, err := os.Open(filepath.Join("/proc", strconv.Itoa(pid), "exe"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open executable: %v", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	var writer io.Writer = hash
+	var temp *os.File
+	if recoverDir != "" {
+		temp, err = os.CreateTemp(recoverDir, "recovering-*")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create recovery file: %v", err)
+		}
+		defer os.Remove(temp.Name()) // No-op once renamed
+		defer temp.Close()
+		writer = io.MultiWriter(hash, temp)
+	}
+	if _, err := io.Copy(writer, file); err != nil {
+		return "", "", fmt.Errorf("failed to read executable: %v", err)
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if temp == nil {
+		return sum, "", nil
+	}
+
+	if err := temp.Close(); err != nil {
+		return sum, "", fmt.Errorf("failed to write recovery file: %v", err)
+	}
+	recovered := filepath.Join(recoverDir, sum+".bin")
+	if _, err := os.Stat(recovered); err == nil {
+		return sum, recovered, nil // Already recovered from another process
+	}
+	if err := os.Rename(temp.Name(), recovered); err != nil {
+		return sum, "", fmt.Errorf("failed to save recovery file: %v", err)
+	}
+	return sum, recovered, nil
+}
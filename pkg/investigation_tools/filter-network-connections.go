@@ -0,0 +1,317 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"fmt"
+	"net/netip"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// PortRange is an inclusive range of ports; a single port has Low == High.
+type PortRange struct {
+	Low  uint16
+	High uint16
+}
+
+// ConnectionFilter selects network connections. Empty fields match everything.
+// A connection must match every non-empty field, and any one value within a field.
+type ConnectionFilter struct {
+	RemoteAddresses []netip.Prefix // Remote address in one of these prefixes (an exact IP is a /32 or /128)
+	LocalAddresses  []netip.Prefix // Local address in one of these prefixes
+	RemotePorts     []PortRange
+	LocalPorts      []PortRange
+	Ports           []PortRange // Local or remote port in one of these ranges
+	States          []string    // TCP states (e.g., "ESTABLISHED", "LISTEN"); case-insensitive
+	Protocols       []string    // "tcp" and "udp" match both families; "tcp4", "tcp6", "udp4", "udp6" and "unix" match exactly
+	PIDs            []string
+	ProcessNames    []string // Process names or glob patterns (e.g., "python*"); case-insensitive, ".exe" is optional
+}
+
+// stateAliases maps the state names used by ss and netstat to the names in GetSocketTable results.
+var stateAliases = map[string]string{
+	"ESTAB":     "ESTABLISHED",
+	"LISTENING": "LISTEN",
+}
+
+// ParseConnectionFilter parses a filter specification.
+//
+// Description:
+// - The specification is a list of key=value terms separated by spaces; values of one key are separated by commas.
+// - Keys: remote (or ip), local, rport, lport, port, state, proto, pid and process.
+// - Addresses are IPv4 or IPv6 addresses or CIDR prefixes; IPv4-mapped IPv6 addresses match IPv4 prefixes.
+// - Ports are single ports or ranges (e.g., "1-1024").
+// - A bare address or prefix without a key is treated as remote=<value>.
+//
+// Parameters:
+// - spec (string): The filter specification.
+//
+// Returns:
+// - ConnectionFilter: The parsed filter.
+// - error: An error if a key or value is invalid.
+//
+// Example Usage:
+// ```go
+// filter, err := ParseConnectionFilter("remote=10.0.0.0/8,2001:db8::/32 rport=443,8000-8999 state=established proto=tcp")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func ParseConnectionFilter(spec string) (ConnectionFilter, error) {
+	var filter ConnectionFilter
+	for _, term := range strings.Fields(spec) {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok {
+			key, value = "remote", term
+		}
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			switch strings.ToLower(key) {
+			case "remote", "ip":
+				prefix, err := parseAddressPrefix(item)
+				if err != nil {
+					return filter, err
+				}
+				filter.RemoteAddresses = append(filter.RemoteAddresses, prefix)
+			case "local":
+				prefix, err := parseAddressPrefix(item)
+				if err != nil {
+					return filter, err
+				}
+				filter.LocalAddresses = append(filter.LocalAddresses, prefix)
+			case "rport", "lport", "port":
+				portRange, err := parsePortRange(item)
+				if err != nil {
+					return filter, err
+				}
+				switch strings.ToLower(key) {
+				case "rport":
+					filter.RemotePorts = append(filter.RemotePorts, portRange)
+				case "lport":
+					filter.LocalPorts = append(filter.LocalPorts, portRange)
+				default:
+					filter.Ports = append(filter.Ports, portRange)
+				}
+			case "state":
+				filter.States = append(filter.States, item)
+			case "proto", "protocol":
+				switch strings.ToLower(item) {
+				case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix":
+				default:
+					return filter, fmt.Errorf("invalid protocol: %s", item)
+				}
+				filter.Protocols = append(filter.Protocols, item)
+			case "pid":
+				if _, err := strconv.Atoi(item); err != nil {
+					return filter, fmt.Errorf("invalid PID: %s", item)
+				}
+				filter.PIDs = append(filter.PIDs, item)
+			case "process", "name":
+				filter.ProcessNames = append(filter.ProcessNames, item)
+			default:
+				return filter, fmt.Errorf("unknown filter key: %s", key)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// Matches reports whether a connection passes the filter.
+//
+// Parameters:
+// - connection (NetworkConnection): The connection to test.
+//
+// Returns:
+// - bool: True if the connection matches every non-empty field of the filter.
+//
+// Example Usage:
+// ```go
+// filter := ConnectionFilter{States: []string{"LISTEN"}}
+// fmt.Println(filter.Matches(NetworkConnection{Protocol: "tcp", State: "LISTEN"}))
+// ```
+func (filter ConnectionFilter) Matches(connection NetworkConnection) bool {
+	if len(filter.RemoteAddresses) > 0 && !addressInPrefixes(connection.RemoteAddress, filter.RemoteAddresses) {
+		return false
+	}
+	if len(filter.LocalAddresses) > 0 && !addressInPrefixes(connection.LocalAddress, filter.LocalAddresses) {
+		return false
+	}
+	if len(filter.RemotePorts) > 0 && !portInRanges(connection.RemotePort, filter.RemotePorts) {
+		return false
+	}
+	if len(filter.LocalPorts) > 0 && !portInRanges(connection.LocalPort, filter.LocalPorts) {
+		return false
+	}
+	if len(filter.Ports) > 0 && !portInRanges(connection.LocalPort, filter.Ports) && !portInRanges(connection.RemotePort, filter.Ports) {
+		return false
+	}
+	if len(filter.States) > 0 && !matchesAny(filter.States, func(state string) bool {
+		return normalizeState(state) == normalizeState(connection.State)
+	}) {
+		return false
+	}
+	if len(filter.Protocols) > 0 && !matchesAny(filter.Protocols, func(protocol string) bool {
+		return protocolMatches(strings.ToLower(protocol), connection.Protocol)
+	}) {
+		return false
+	}
+	if len(filter.PIDs) > 0 && !matchesAny(filter.PIDs, func(pid string) bool {
+		return pid == connection.ProcessId
+	}) {
+		return false
+	}
+	if len(filter.ProcessNames) > 0 && !matchesAny(filter.ProcessNames, func(pattern string) bool {
+		return processNameMatches(pattern, connection.ProcessName)
+	}) {
+		return false
+	}
+	return true
+}
+
+// GetNetworkConnections lists the system's sockets that match a filter.
+//
+// Description:
+// - Reads the socket table with GetSocketTable and keeps the connections that match the filter.
+//
+// Parameters:
+// - filter (ConnectionFilter): The filter to apply; an empty filter returns every socket.
+//
+// Returns:
+// - []NetworkConnection: The matching connections.
+// - error: An error if the socket table cannot be read.
+//
+// Example Usage:
+// ```go
+// filter, _ := ParseConnectionFilter("state=established process=python*")
+// connections, err := GetNetworkConnections(filter)
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    fmt.Println("Connections:", connections)
+//	}
+//
+// ```
+func GetNetworkConnections(filter ConnectionFilter) ([]NetworkConnection, error) {
+	sockets, err := GetSocketTable()
+	if err != nil {
+		return nil, err
+	}
+
+	var connections []NetworkConnection
+	for _, socket := range sockets {
+		if filter.Matches(socket) {
+			connections = append(connections, socket)
+		}
+	}
+	return connections, nil
+}
+
+// parseAddressPrefix parses an IP address or CIDR prefix; an address becomes a single-address prefix.
+func parseAddressPrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR prefix: %s", value)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address: %s", value)
+	}
+	addr = addr.WithZone("")
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parsePortRange parses a port ("443") or an inclusive port range ("8000-8999").
+func parsePortRange(value string) (PortRange, error) {
+	low, high, isRange := strings.Cut(value, "-")
+	if !isRange {
+		high = low
+	}
+	lowPort, lowErr := strconv.ParseUint(low, 10, 16)
+	highPort, highErr := strconv.ParseUint(high, 10, 16)
+	if lowErr != nil || highErr != nil || lowPort > highPort {
+		return PortRange{}, fmt.Errorf("invalid port or port range: %s", value)
+	}
+	return PortRange{Low: uint16(lowPort), High: uint16(highPort)}, nil
+}
+
+// addressInPrefixes reports whether an address string falls in one of the prefixes.
+func addressInPrefixes(address string, prefixes []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	addr = addr.WithZone("")
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) || prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// portInRanges reports whether a port string falls in one of the ranges.
+func portInRanges(port string, ranges []PortRange) bool {
+	number, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return false
+	}
+	for _, portRange := range ranges {
+		if uint16(number) >= portRange.Low && uint16(number) <= portRange.High {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeState converts a state name to the upper-case, underscore form used by GetSocketTable.
+func normalizeState(state string) string {
+	state = strings.ToUpper(strings.ReplaceAll(state, "-", "_"))
+	if alias, ok := stateAliases[state]; ok {
+		return alias
+	}
+	return state
+}
+
+// protocolMatches reports whether a protocol filter value matches a connection's protocol.
+func protocolMatches(filter, protocol string) bool {
+	switch filter {
+	case "tcp", "udp":
+		return strings.TrimSuffix(protocol, "6") == filter
+	case "tcp4", "udp4":
+		return protocol == strings.TrimSuffix(filter, "4")
+	}
+	return protocol == filter
+}
+
+// processNameMatches reports whether a process name matches a name or glob pattern, ignoring case and ".exe".
+func processNameMatches(pattern, name string) bool {
+	pattern = strings.TrimSuffix(strings.ToLower(pattern), ".exe")
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	// path.Match stops "*" at "/", which appears in Linux kernel thread names such as "kworker/0:1".
+	if match, _ := path.Match(strings.ReplaceAll(pattern, "/", "\x00"), strings.ReplaceAll(name, "/", "\x00")); match {
+		return true
+	}
+	return pattern == name
+}
+
+// matchesAny reports whether match returns true for any of the values.
+func matchesAny(values []string, match func(string) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProcessFilter selects processes. Empty fields match everything.
+// A process must match every non-empty field, and any one value within a field.
+type ProcessFilter struct {
+	Users       []string // User names or UIDs
+	Names       []string // Process names or glob patterns (e.g., "python*"); case-insensitive, ".exe" is optional
+	ParentPIDs  []int    // Children of these processes
+	Descendants bool     // With ParentPIDs, also match grandchildren and further descendants
+}
+
+// FilterProcesses keeps the processes that match a filter.
+//
+// Description:
+// - Names match the process name or the base name of its executable, so renamed processes are still found.
+// - With Descendants, the whole subtree below each parent is matched, following PPIDs in the given list.
+//
+// Parameters:
+// - processes ([]Process): The processes to filter, usually from GetProcesses.
+// - filter (ProcessFilter): The filter to apply.
+//
+// Returns:
+// - []Process: The matching processes, in their original order.
+//
+// Example Usage:
+// ```go
+// processes, _ := GetProcesses()
+// sshChildren := FilterProcesses(processes, ProcessFilter{Names: []string{"bash", "sh"}, Users: []string{"root"}})
+// ```
+func FilterProcesses(processes []Process, filter ProcessFilter) []Process {
+	parents := map[int]bool{}
+	for _, pid := range filter.ParentPIDs {
+		parents[pid] = true
+	}
+	if filter.Descendants && len(parents) > 0 {
+		children := processChildren(processes)
+		queue := append([]int(nil), filter.ParentPIDs...)
+		for len(queue) > 0 {
+			pid := queue[0]
+			queue = queue[1:]
+			for _, child := range children[pid] {
+				if !parents[child.PID] {
+					parents[child.PID] = true
+					queue = append(queue, child.PID)
+				}
+			}
+		}
+	}
+
+	var matches []Process
+	for _, process := range processes {
+		if len(filter.Users) > 0 && !matchesAny(filter.Users, func(name string) bool {
+			return name == process.UID || strings.EqualFold(name, process.User)
+		}) {
+			continue
+		}
+		if len(filter.Names) > 0 && !matchesAny(filter.Names, func(pattern string) bool {
+			return processNameMatches(pattern, process.Name) ||
+				(process.ExePath != "" && processNameMatches(pattern, exeBaseName(process.ExePath)))
+		}) {
+			continue
+		}
+		if len(parents) > 0 && !parents[process.PPID] {
+			continue
+		}
+		matches = append(matches, process)
+	}
+	return matches
+}
+
+// RenderProcessTree draws processes as a tree, one process per line.
+//
+// Description:
+// - Processes whose parent is not in the list are drawn as roots, so a filtered list renders as a forest.
+// - Each line shows the PID, name, user and command line; kernel threads, which have no command line, show their name in brackets.
+// - Children are sorted by PID.
+//
+// Parameters:
+// - processes ([]Process): The processes to draw.
+//
+// Returns:
+// - string: The tree, with a trailing newline after each process.
+//
+// Example Usage:
+// ```go
+// processes, _ := GetProcesses()
+// fmt.Print(RenderProcessTree(processes))
+// ```
+func RenderProcessTree(processes []Process) string {
+	present := map[int]bool{}
+	for _, process := range processes {
+		present[process.PID] = true
+	}
+	children := processChildren(processes)
+
+	var roots []Process
+	for _, process := range processes {
+		if !present[process.PPID] || process.PPID == process.PID {
+			roots = append(roots, process)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].PID < roots[j].PID })
+
+	var builder strings.Builder
+	drawn := map[int]bool{}
+	var draw func(process Process, prefix, branch, indent string)
+	draw = func(process Process, prefix, branch, indent string) {
+		if drawn[process.PID] {
+			return // PID reuse can produce a cycle
+		}
+		drawn[process.PID] = true
+		builder.WriteString(prefix + branch + formatProcessLine(process) + "\n")
+		kids := children[process.PID]
+		for i, child := range kids {
+			if i == len(kids)-1 {
+				draw(child, prefix+indent, "└─ ", "   ")
+			} else {
+				draw(child, prefix+indent, "├─ ", "│  ")
+			}
+		}
+	}
+	for _, root := range roots {
+		draw(root, "", "", "")
+	}
+	return builder.String()
+}
+
+// processChildren indexes processes by their parent PID, sorted by PID.
+func processChildren(processes []Process) map[int][]Process {
+	children := map[int][]Process{}
+	for _, process := range processes {
+		if process.PPID != process.PID {
+			children[process.PPID] = append(children[process.PPID], process)
+		}
+	}
+	for _, kids := range children {
+		sort.Slice(kids, func(i, j int) bool { return kids[i].PID < kids[j].PID })
+	}
+	return children
+}
+
+// formatProcessLine formats a process for RenderProcessTree.
+func formatProcessLine(process Process) string {
+	line := fmt.Sprintf("%d %s", process.PID, process.Name)
+	if process.User != "" {
+		line += " (" + process.User + ")"
+	} else if process.UID != "" {
+		line += " (" + process.UID + ")"
+	}
+	if process.Cmdline != "" {
+		line += "  " + strings.Join(strings.Fields(process.Cmdline), " ") // Arguments may contain newlines
+	} else {
+		line += "  [" + process.Name + "]"
+	}
+	return line
+}
+
+// exeBaseName returns the file name of an executable path, on either platform.
+func exeBaseName(exePath string) string {
+	return exePath[strings.LastIndexAny(exePath, `/\`)+1:]
+}
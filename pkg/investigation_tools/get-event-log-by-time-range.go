@@ -1,5 +1,7 @@
 // This module is Windows-specific.
 
+//go:build windows
+
 package investigation_tools
 
 import (
@@ -5,36 +5,40 @@ package investigation_tools
 import (
 	"fmt"
 	"log"
-	"net"
+	"net/netip"
 	"os"
-	"os/exec"
-	"runtime"
-	"strings"
 )
 
 // NetworkConnection represents a network connection and its associated process.
 type NetworkConnection struct {
-	LocalAddress  string
+	Protocol      string // "tcp", "tcp6", "udp", "udp6" or "unix"
+	LocalAddress  string // Local IP address, or the socket path for Unix domain sockets
 	LocalPort     string
 	RemoteAddress string
 	RemotePort    string
 	State         string
 	ProcessName   string
 	ProcessId     string
+	ExePath       string // Executable of the owning process (Linux only)
+	Cmdline       string // Command line of the owning process (Linux only)
+	UID           string // User ID owning the socket (Linux only)
+	Inode         string // Socket inode (Linux only)
 }
 
 // GetNetworkConnectionProcess retrieves network connections and their associated processes.
 //
 // Description:
+// - Lists the system's sockets with GetSocketTable and returns those whose remote address is one of the IP addresses.
+// - Each entry may be an IPv4 or IPv6 address, matched exactly, or a CIDR prefix such as "10.0.0.0/8".
 // - On Windows, uses `netstat` and `tasklist` to retrieve connection details.
-// - On Linux, uses `ss` and `ps` to retrieve connection details.
+// - On Linux, reads /proc natively and also fills in the executable path, command line, UID and inode.
 //
 // Parameters:
 // - ipAddresses ([]string): A list of IP addresses to filter connections.
 //
 // Returns:
 // - []NetworkConnection: A slice of network connections and their associated processes.
-// - error: An error if the query fails.
+// - error: An error if an IP address is invalid or the query fails.
 //
 // Example Usage:
 // ```go
@@ -49,86 +53,31 @@ type NetworkConnection struct {
 //
 // ```
 func GetNetworkConnectionProcess(ipAddresses []string) ([]NetworkConnection, error) {
-	var results []NetworkConnection
-
+	prefixes := make([]netip.Prefix, 0, len(ipAddresses))
 	for _, ipAddress := range ipAddresses {
-		log.Printf("Checking connections for IP address: %s", ipAddress)
-
-		var cmd *exec.Cmd
-		if runtime.GOOS == "windows" {
-			cmd = exec.Command("netstat", "-ano")
-		} else {
-			cmd = exec.Command("ss", "-tanp")
-		}
-
-		output, err := cmd.Output()
+		prefix, err := parseAddressPrefix(ipAddress)
 		if err != nil {
-			return nil, fmt.Errorf("failed to execute command: %v", err)
+			return nil, err
 		}
+		prefixes = append(prefixes, prefix)
+	}
 
-		lines := strings.Split(string(output), "\n")
-		found := false
-		for _, line := range lines {
-			fields := strings.Fields(line)
-			if runtime.GOOS == "windows" {
-				if len(fields) < 5 || !strings.Contains(fields[2], ipAddress) {
-					continue
-				}
-			} else {
-				if len(fields) < 6 || !strings.Contains(fields[4], ipAddress) {
-					continue
-				}
-			}
+	sockets, err := GetSocketTable()
+	if err != nil {
+		return nil, err
+	}
 
-			found = true
-			var remoteAddr, state, processId string
-			if runtime.GOOS == "windows" {
-				remoteAddr = fields[2]
-				state = fields[3]
-				processId = fields[4]
-			} else {
-				remoteAddr = fields[4]
-				state = fields[1]
-				processId = strings.TrimPrefix(fields[5], "pid=")
-			}
+	var results []NetworkConnection
+	for i, ipAddress := range ipAddresses {
+		log.Printf("Checking connections for IP address: %s", ipAddress)
 
-			remoteHost, remotePort, err := net.SplitHostPort(remoteAddr)
-			if err != nil {
-				log.Printf("Failed to parse remote address %s: %v", remoteAddr, err)
+		found := false
+		for _, socket := range sockets {
+			if !addressInPrefixes(socket.RemoteAddress, prefixes[i:i+1]) {
 				continue
 			}
-
-			processName := "Unknown"
-			if runtime.GOOS == "windows" {
-				cmd = exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %s", processId))
-				tasklistOutput, err := cmd.Output()
-				if err == nil {
-					tasklistLines := strings.Split(string(tasklistOutput), "\n")
-					if len(tasklistLines) > 3 {
-						processName = strings.Fields(tasklistLines[3])[0]
-					}
-				} else {
-					log.Printf("Failed to retrieve process name for PID %s: %v", processId, err)
-				}
-			} else {
-				cmd = exec.Command("ps", "-p", processId, "-o", "comm=")
-				psOutput, err := cmd.Output()
-				if err == nil {
-					processName = strings.TrimSpace(string(psOutput))
-				} else {
-					log.Printf("Failed to retrieve process name for PID %s: %v", processId, err)
-				}
-			}
-
-			results = append(results, NetworkConnection{
-				LocalAddress:  fields[1],
-				LocalPort:     strings.Split(fields[1], ":")[1],
-				RemoteAddress: remoteHost,
-				RemotePort:    remotePort,
-				State:         state,
-				ProcessName:   processName,
-				ProcessId:     processId,
-			})
+			found = true
+			results = append(results, socket)
 		}
 
 		if !found {
@@ -160,7 +109,7 @@ func main() {
 
 	// Print results
 	for _, conn := range results {
-		fmt.Printf("Local: %s:%s, Remote: %s:%s, State: %s, Process: %s (PID: %s)\n",
-			conn.LocalAddress, conn.LocalPort, conn.RemoteAddress, conn.RemotePort, conn.State, conn.ProcessName, conn.ProcessId)
+		fmt.Printf("%s Local: %s:%s, Remote: %s:%s, State: %s, Process: %s (PID: %s)\n",
+			conn.Protocol, conn.LocalAddress, conn.LocalPort, conn.RemoteAddress, conn.RemotePort, conn.State, conn.ProcessName, conn.ProcessId)
 	}
 }
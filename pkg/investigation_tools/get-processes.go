@@ -0,0 +1,271 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Process is a running process.
+type Process struct {
+	PID        int               `json:"pid"`
+	PPID       int               `json:"ppid"`
+	Name       string            `json:"name"`
+	ExePath    string            `json:"exe"`
+	Cmdline    string            `json:"cmdline"`
+	Cwd        string            `json:"cwd,omitempty"` // Linux only
+	UID        string            `json:"uid,omitempty"` // Real user ID (Linux only)
+	User       string            `json:"user,omitempty"`
+	StartTime  time.Time         `json:"start_time"`
+	State      string            `json:"state,omitempty"` // e.g., "S (sleeping)" (Linux only)
+	Threads    int               `json:"threads"`
+	RSS        int64             `json:"rss"`                  // Resident memory in bytes
+	Cgroup     string            `json:"cgroup,omitempty"`     // Linux only
+	Namespaces map[string]string `json:"namespaces,omitempty"` // Namespace IDs by type, e.g., "net": "4026531840" (Linux only)
+}
+
+// clockTicks is USER_HZ, the unit of the times in /proc/<pid>/stat. The kernel fixes it at 100 on every architecture Go supports.
+const clockTicks = 100
+
+// GetProcesses lists the running processes.
+//
+// Description:
+// - On Linux, reads /proc/<pid>/stat, status, comm, exe, cmdline, cwd, cgroup and ns without running any external command.
+// - Without root, the executable, working directory and namespaces of other users' processes are left empty.
+// - On Windows, queries Win32_Process through PowerShell; the user, working directory, state, cgroup and namespaces are left empty.
+// - Processes that exit while being read are skipped.
+//
+// Parameters: None
+//
+// Returns:
+// - []Process: The processes, sorted by PID.
+// - error: An error if the process list cannot be read.
+//
+// Example Usage:
+// ```go
+// processes, err := GetProcesses()
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, process := range processes {
+//	        fmt.Println(process.PID, process.PPID, process.User, process.Name, process.ExePath)
+//	    }
+//	}
+//
+// ```
+func GetProcesses() ([]Process, error) {
+	var processes []Process
+	var err error
+
+	switch runtime.GOOS {
+	case "windows":
+		processes, err = getWindowsProcesses()
+	case "linux":
+		processes, err = getLinuxProcesses()
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+	return processes, nil
+}
+
+func getWindowsProcesses() ([]Process, error) {
+	script := "ConvertTo-Json -Compress -InputObject @(Get-CimInstance -ClassName Win32_Process | Select-Object " +
+		"ProcessId, ParentProcessId, Name, ExecutablePath, CommandLine, ThreadCount, WorkingSetSize, " +
+		"@{Name='CreationDate'; Expression={ if ($_.CreationDate) { $_.CreationDate.ToUniversalTime().ToString('o') } }})"
+	output, err := exec.Command("powershell", "-NoProfile", "-Command", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve processes: %v", err)
+	}
+
+	var records []struct {
+		ProcessId       int
+		ParentProcessId int
+		Name            string
+		ExecutablePath  string
+		CommandLine     string
+		ThreadCount     int
+		WorkingSetSize  int64
+		CreationDate    string
+	}
+	if err := json.Unmarshal(output, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse processes: %v", err)
+	}
+
+	var processes []Process
+	for _, record := range records {
+		startTime, _ := time.Parse(time.RFC3339Nano, record.CreationDate)
+		processes = append(processes, Process{
+			PID:       record.ProcessId,
+			PPID:      record.ParentProcessId,
+			Name:      record.Name,
+			ExePath:   record.ExecutablePath,
+			Cmdline:   record.CommandLine,
+			StartTime: startTime,
+			Threads:   record.ThreadCount,
+			RSS:       record.WorkingSetSize,
+		})
+	}
+	return processes, nil
+}
+
+func getLinuxProcesses() ([]Process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /proc: %v", err)
+	}
+	bootTime, err := readBootTime()
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[string]string{}
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		process, err := readLinuxProcess(pid, bootTime)
+		if err != nil {
+			continue // Exited while being read
+		}
+		if process.UID != "" {
+			name, ok := users[process.UID]
+			if !ok {
+				if account, err := user.LookupId(process.UID); err == nil {
+					name = account.Username
+				}
+				users[process.UID] = name
+			}
+			process.User = name
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// readLinuxProcess reads one process from /proc. Only a missing stat file is an error; other files may be unreadable.
+func readLinuxProcess(pid int, bootTime time.Time) (Process, error) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, err
+	}
+
+	// The name in parentheses may itself contain spaces and parentheses, so split after the last ')'.
+	statText := string(stat)
+	end := strings.LastIndexByte(statText, ')')
+	if end < 0 {
+		return Process{}, fmt.Errorf("invalid stat for PID %d", pid)
+	}
+	// Fields from the state on: state ppid pgrp session tty_nr tpgid flags minflt cminflt majflt cmajflt
+	// utime stime cutime cstime priority nice num_threads itrealvalue starttime vsize rss ...
+	fields := strings.Fields(statText[end+1:])
+	if len(fields) < 22 {
+		return Process{}, fmt.Errorf("invalid stat for PID %d", pid)
+	}
+
+	process := Process{PID: pid, Namespaces: map[string]string{}}
+	process.Name = statText[strings.IndexByte(statText, '(')+1 : end]
+	process.State = fields[0]
+	process.PPID, _ = strconv.Atoi(fields[1])
+	process.Threads, _ = strconv.Atoi(fields[17])
+	if ticks, err := strconv.ParseInt(fields[19], 10, 64); err == nil {
+		process.StartTime = bootTime.Add(time.Duration(ticks) * time.Second / clockTicks)
+	}
+	if pages, err := strconv.ParseInt(fields[21], 10, 64); err == nil {
+		process.RSS = pages * int64(os.Getpagesize())
+	}
+
+	if status, err := os.ReadFile(filepath.Join(dir, "status")); err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			key, value, _ := strings.Cut(line, ":")
+			value = strings.TrimSpace(value)
+			switch key {
+			case "State":
+				process.State = value
+			case "Uid":
+				if ids := strings.Fields(value); len(ids) > 0 {
+					process.UID = ids[0]
+				}
+			}
+		}
+	}
+	if exePath, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		process.ExePath = exePath
+	}
+	if cwd, err := os.Readlink(filepath.Join(dir, "cwd")); err == nil {
+		process.Cwd = cwd
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		process.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	if cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup")); err == nil {
+		process.Cgroup = parseProcCgroup(string(cgroup))
+	}
+	if namespaces, err := os.ReadDir(filepath.Join(dir, "ns")); err == nil {
+		for _, namespace := range namespaces {
+			// Links look like "net:[4026531840]".
+			target, err := os.Readlink(filepath.Join(dir, "ns", namespace.Name()))
+			if err != nil {
+				continue
+			}
+			if _, id, ok := strings.Cut(target, ":["); ok {
+				process.Namespaces[namespace.Name()] = strings.TrimSuffix(id, "]")
+			}
+		}
+	}
+	return process, nil
+}
+
+// parseProcCgroup returns the cgroup v2 path of /proc/<pid>/cgroup, or the v1 "controllers:path" entries joined by ";".
+func parseProcCgroup(content string) string {
+	var entries []string
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			return parts[2]
+		}
+		entries = append(entries, parts[1]+":"+parts[2])
+	}
+	return strings.Join(entries, ";")
+}
+
+// readBootTime reads the system boot time from the btime line of /proc/stat.
+func readBootTime() (time.Time, error) {
+	stat, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read /proc/stat: %v", err)
+	}
+	for _, line := range strings.Split(string(stat), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				break
+			}
+			return time.Unix(seconds, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to read the boot time from /proc/stat")
+}
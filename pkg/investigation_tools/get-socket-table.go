@@ -0,0 +1,371 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// tcpStates maps the state codes in /proc/net/tcp and tcp6 to their names.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+	"0C": "NEW_SYN_RECV",
+}
+
+// unixStates maps the state codes in /proc/net/unix to their names.
+var unixStates = map[string]string{
+	"01": "UNCONNECTED",
+	"02": "CONNECTING",
+	"03": "CONNECTED",
+	"04": "DISCONNECTING",
+}
+
+// socketProcess is the process that owns a socket.
+type socketProcess struct {
+	pid     string
+	name    string
+	exePath string
+	cmdline string
+	uid     string
+}
+
+// GetSocketTable lists every socket on the system with its owning process.
+//
+// Description:
+// - On Linux, parses /proc/net/tcp, tcp6, udp, udp6 and unix and maps socket inodes to processes by scanning /proc/*/fd, without running any external command.
+// - On Linux, fills in the process name, executable path, command line and socket owner UID.
+// - Without root, sockets owned by other users' processes are listed with the process name "Unknown".
+// - On Windows, uses `netstat -ano` and a single `tasklist` call; TCP and UDP sockets are listed.
+//
+// Parameters: None
+//
+// Returns:
+// - []NetworkConnection: Every socket, including listening and Unix domain sockets.
+// - error: An error if the socket table cannot be read.
+//
+// Example Usage:
+// ```go
+// sockets, err := GetSocketTable()
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, socket := range sockets {
+//	        fmt.Println(socket.Protocol, socket.LocalAddress, socket.LocalPort, socket.State, socket.ProcessName)
+//	    }
+//	}
+//
+// ```
+func GetSocketTable() ([]NetworkConnection, error) {
+	switch runtime.GOOS {
+	case "windows":
+		return getWindowsSockets()
+	case "linux":
+		return getLinuxSockets()
+	default:
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+}
+
+func getWindowsSockets() ([]NetworkConnection, error) {
+	output, err := exec.Command("netstat", "-ano").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command: %v", err)
+	}
+
+	// Look up every process name at once instead of running tasklist per connection.
+	processNames := map[string]string{}
+	if tasklistOutput, err := exec.Command("tasklist", "/FO", "CSV", "/NH").Output(); err == nil {
+		records, _ := csv.NewReader(strings.NewReader(string(tasklistOutput))).ReadAll()
+		for _, record := range records {
+			if len(record) >= 2 {
+				processNames[record[1]] = record[0]
+			}
+		}
+	}
+
+	var sockets []NetworkConnection
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		var protocol, state, processId string
+		switch {
+		case len(fields) == 5 && fields[0] == "TCP":
+			protocol, state, processId = "tcp", fields[3], fields[4]
+		case len(fields) == 4 && fields[0] == "UDP":
+			protocol, processId = "udp", fields[3]
+		default:
+			continue
+		}
+
+		localHost, localPort, err := net.SplitHostPort(fields[1])
+		if err != nil {
+			continue
+		}
+		remoteHost, remotePort, err := net.SplitHostPort(fields[2])
+		if err != nil {
+			continue
+		}
+		if strings.Contains(localHost, ":") {
+			protocol += "6"
+		}
+
+		processName, ok := processNames[processId]
+		if !ok {
+			processName = "Unknown"
+		}
+		sockets = append(sockets, NetworkConnection{
+			Protocol:      protocol,
+			LocalAddress:  localHost,
+			LocalPort:     localPort,
+			RemoteAddress: remoteHost,
+			RemotePort:    remotePort,
+			State:         state,
+			ProcessName:   processName,
+			ProcessId:     processId,
+		})
+	}
+
+	return sockets, nil
+}
+
+func getLinuxSockets() ([]NetworkConnection, error) {
+	var sockets []NetworkConnection
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		entries, err := parseProcNetInet(filepath.Join("/proc/net", protocol), protocol)
+		if err != nil {
+			if protocol == "tcp" {
+				return nil, err
+			}
+			continue // IPv6 or UDP may be unavailable
+		}
+		sockets = append(sockets, entries...)
+	}
+	if entries, err := parseProcNetUnix("/proc/net/unix"); err == nil {
+		sockets = append(sockets, entries...)
+	}
+
+	owners := mapSocketInodes()
+	processes := map[string]socketProcess{}
+	for i := range sockets {
+		sockets[i].ProcessName = "Unknown"
+		pid, ok := owners[sockets[i].Inode]
+		if !ok {
+			continue
+		}
+		process, ok := processes[pid]
+		if !ok {
+			process = readSocketProcess(pid)
+			processes[pid] = process
+		}
+		sockets[i].ProcessId = process.pid
+		sockets[i].ProcessName = process.name
+		sockets[i].ExePath = process.exePath
+		sockets[i].Cmdline = process.cmdline
+		if sockets[i].UID == "" {
+			sockets[i].UID = process.uid // /proc/net/unix has no owner column
+		}
+	}
+
+	return sockets, nil
+}
+
+// parseProcNetInet parses /proc/net/tcp, tcp6, udp or udp6.
+func parseProcNetInet(path, protocol string) ([]NetworkConnection, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var sockets []NetworkConnection
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		localAddress, localPort, err := decodeProcNetAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		remoteAddress, remotePort, err := decodeProcNetAddress(fields[2])
+		if err != nil {
+			continue
+		}
+
+		state := tcpStates[fields[3]]
+		if strings.HasPrefix(protocol, "udp") {
+			// UDP sockets only use the established and close states.
+			state = "UNCONN"
+			if fields[3] == "01" {
+				state = "ESTABLISHED"
+			}
+		}
+
+		sockets = append(sockets, NetworkConnection{
+			Protocol:      protocol,
+			LocalAddress:  localAddress.String(),
+			LocalPort:     strconv.Itoa(int(localPort)),
+			RemoteAddress: remoteAddress.String(),
+			RemotePort:    strconv.Itoa(int(remotePort)),
+			State:         state,
+			UID:           fields[7],
+			Inode:         fields[9],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	return sockets, nil
+}
+
+// parseProcNetUnix parses /proc/net/unix.
+func parseProcNetUnix(path string) ([]NetworkConnection, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var sockets []NetworkConnection
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// Num RefCount Protocol Flags Type St Inode Path
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) < 7 {
+			continue
+		}
+		state := unixStates[fields[5]]
+		if flags, err := strconv.ParseUint(fields[3], 16, 32); err == nil && flags&0x10000 != 0 {
+			state = "LISTEN" // __SO_ACCEPTCON
+		}
+		// The path may contain spaces, so it is the rest of the line after the inode and the single space the kernel prints.
+		rest := line
+		for i := 0; i < 7; i++ {
+			rest = strings.TrimLeft(rest, " ")
+			rest = rest[len(fields[i]):]
+		}
+		socketPath := strings.TrimPrefix(rest, " ")
+
+		sockets = append(sockets, NetworkConnection{
+			Protocol:     "unix",
+			LocalAddress: socketPath,
+			State:        state,
+			Inode:        fields[6],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	return sockets, nil
+}
+
+// decodeProcNetAddress decodes an "ADDRESS:PORT" field of /proc/net/tcp.
+// The address is printed as 32-bit words in host byte order; the port is printed as a number.
+func decodeProcNetAddress(field string) (netip.Addr, uint16, error) {
+	addressHex, portHex, ok := strings.Cut(field, ":")
+	if !ok {
+		return netip.Addr{}, 0, fmt.Errorf("invalid address: %s", field)
+	}
+	raw, err := hex.DecodeString(addressHex)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return netip.Addr{}, 0, fmt.Errorf("invalid address: %s", field)
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return netip.Addr{}, 0, fmt.Errorf("invalid port: %s", field)
+	}
+
+	address := make([]byte, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.NativeEndian.PutUint32(address[i:], binary.BigEndian.Uint32(raw[i:]))
+	}
+	addr, _ := netip.AddrFromSlice(address)
+	return addr, uint16(port), nil
+}
+
+// mapSocketInodes maps socket inodes to the PID of a process holding them, by reading the /proc/*/fd links.
+func mapSocketInodes() map[string]string {
+	owners := map[string]string{}
+	processes, err := os.ReadDir("/proc")
+	if err != nil {
+		return owners
+	}
+	for _, process := range processes {
+		pid := process.Name()
+		if _, err := strconv.Atoi(pid); err != nil {
+			continue
+		}
+		descriptors, err := os.ReadDir(filepath.Join("/proc", pid, "fd"))
+		if err != nil {
+			continue // Exited, or owned by another user
+		}
+		for _, descriptor := range descriptors {
+			target, err := os.Readlink(filepath.Join("/proc", pid, "fd", descriptor.Name()))
+			if err != nil {
+				continue
+			}
+			if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+				inode = strings.TrimSuffix(inode, "]")
+				if _, exists := owners[inode]; !exists {
+					owners[inode] = pid
+				}
+			}
+		}
+	}
+	return owners
+}
+
+// readSocketProcess reads the name, executable, command line and real UID of a process from /proc.
+func readSocketProcess(pid string) socketProcess {
+	process := socketProcess{pid: pid, name: "Unknown"}
+	if comm, err := os.ReadFile(filepath.Join("/proc", pid, "comm")); err == nil {
+		process.name = strings.TrimSpace(string(comm))
+	}
+	if exePath, err := os.Readlink(filepath.Join("/proc", pid, "exe")); err == nil {
+		process.exePath = exePath
+	}
+	if cmdline, err := os.ReadFile(filepath.Join("/proc", pid, "cmdline")); err == nil {
+		process.cmdline = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	if status, err := os.ReadFile(filepath.Join("/proc", pid, "status")); err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			if ids, ok := strings.CutPrefix(line, "Uid:"); ok {
+				if fields := strings.Fields(ids); len(fields) > 0 {
+					process.uid = fields[0]
+				}
+				break
+			}
+		}
+	}
+	return process
+}
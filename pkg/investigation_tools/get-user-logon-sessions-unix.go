@@ -0,0 +1,100 @@
+// This module is for platforms other than Windows (Linux and macOS).
+
+//go:build !windows
+
+package investigation_tools
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Protheophage/GO/pkg/random_utilities"
+)
+
+// wtmpPaths are the logon history files, most complete first; containers often have only utmp.
+var wtmpPaths = []string{"/var/log/wtmp", "/var/run/utmp", "/run/utmp"}
+
+// btmpPath is the failed logon log, readable only by root.
+const btmpPath = "/var/log/btmp"
+
+// GetUserLogonSessions retrieves logon sessions from the Linux wtmp and btmp logs.
+//
+// Description:
+// - Reads /var/log/wtmp, or /var/run/utmp if there is no wtmp, and reconstructs sessions with ReconstructLogonSessions.
+// - Failed logons are read from /var/log/btmp; without root they are skipped with a warning.
+// - Filters sessions by username and logon time; a zero start or end date leaves that side of the range open.
+// - To read files copied from another host, use ReadUtmpFile and ReconstructLogonSessions directly.
+// - macOS stores logons in a different format and is not supported.
+//
+// Parameters:
+// - userName (string): The username to filter (use "*" for all users).
+// - startDate (time.Time): The start of the time range.
+// - endDate (time.Time): The end of the time range.
+//
+// Returns:
+// - []LogonSession: A slice of logon session details, including failed logons.
+// - error: An error if the logon history cannot be read.
+//
+// Example Usage:
+// ```go
+// sessions, err := GetUserLogonSessions("root", time.Now().Add(-24*time.Hour), time.Now())
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, session := range sessions {
+//	        fmt.Println(session.UserName, session.SourceIP, session.LogonTime, session.Duration, session.Status)
+//	    }
+//	}
+//
+// ```
+func GetUserLogonSessions(userName string, startDate, endDate time.Time) ([]LogonSession, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+
+	historyPath := ""
+	for _, path := range wtmpPaths {
+		if _, err := os.Stat(path); err == nil {
+			historyPath = path
+			break
+		}
+	}
+	if historyPath == "" {
+		return nil, fmt.Errorf("no logon history found in %s", strings.Join(wtmpPaths, ", "))
+	}
+	records, err := ReadUtmpFile(historyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []UtmpRecord
+	if _, err := os.Stat(btmpPath); err == nil {
+		failures, err = ReadUtmpFile(btmpPath)
+		if err != nil {
+			log.Printf("Warning: failed logons are unavailable: %v", err)
+		}
+	}
+
+	logonSessions := []LogonSession{}
+	for _, session := range ReconstructLogonSessions(records, failures) {
+		if (!startDate.IsZero() && session.LogonTime.Before(startDate)) || (!endDate.IsZero() && session.LogonTime.After(endDate)) {
+			continue
+		}
+		if userName != "*" && !random_utilities.MatchesWildcard(userName, session.UserName) {
+			continue
+		}
+		logonSessions = append(logonSessions, session)
+	}
+
+	if len(logonSessions) == 0 {
+		log.Println("No logon sessions found for the specified criteria.")
+	}
+
+	return logonSessions, nil
+}
@@ -1,3 +1,7 @@
+// This module is Windows-specific.
+
+//go:build windows
+
 package investigation_tools
 
 import (
@@ -11,18 +15,11 @@ import (
 	"golang.org/x/sys/windows/svc/eventlog"
 )
 
-// LogonSession represents a user logon session.
-type LogonSession struct {
-	UserName  string
-	LogonTime time.Time
-	LogonType string
-}
-
 // GetUserLogonSessions retrieves logon sessions from the Windows Security event log.
 //
 // Description:
 // - Filters logon events (Event ID 4624) based on username and time range.
-// - Parses event data to extract logon details.
+// - Parses event data to extract logon details, including the workstation name and IP address of network logons.
 //
 // Parameters:
 // - userName (string): The username to filter (use "*" for all users).
@@ -82,13 +79,21 @@ func GetUserLogonSessions(userName string, startDate, endDate time.Time) ([]Logo
 			continue
 		}
 
-		var extractedUserName, extractedLogonType string
+		var extractedUserName, extractedLogonType, extractedWorkstation, extractedIP string
 		for _, data := range eventXML.EventData.Data {
 			switch strings.ToLower(data.Name) {
 			case "targetusername":
 				extractedUserName = data.Value
 			case "logontype":
 				extractedLogonType = data.Value
+			case "workstationname":
+				if data.Value != "-" {
+					extractedWorkstation = data.Value
+				}
+			case "ipaddress":
+				if data.Value != "-" { // "-" for local logons
+					extractedIP = data.Value
+				}
 			}
 		}
 
@@ -110,9 +115,11 @@ func GetUserLogonSessions(userName string, startDate, endDate time.Time) ([]Logo
 		}
 
 		logonSessions = append(logonSessions, LogonSession{
-			UserName:  extractedUserName,
-			LogonTime: eventTime,
-			LogonType: extractedLogonType,
+			UserName:   extractedUserName,
+			LogonTime:  eventTime,
+			LogonType:  extractedLogonType,
+			SourceHost: extractedWorkstation,
+			SourceIP:   extractedIP,
 		})
 	}
 
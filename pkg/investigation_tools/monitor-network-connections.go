@@ -0,0 +1,314 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MonitorOptions configures MonitorNetworkConnections. Zero values use the defaults.
+type MonitorOptions struct {
+	Interval         time.Duration    // Time between socket table polls (default: 2s)
+	Filter           ConnectionFilter // Only connections matching the filter are tracked
+	IncludeListening bool             // Also track listening, unconnected and Unix domain sockets
+	ReportExisting   bool             // Emit "opened" events for the connections present at startup
+	CountTimeWait    bool             // Emit "closed" events for new TIME_WAIT sockets of connections that opened and closed between two polls
+}
+
+// ConnectionEvent reports a connection that appeared or disappeared between two polls.
+type ConnectionEvent struct {
+	Time          time.Time         `json:"time"`
+	Event         string            `json:"event"` // "opened" or "closed"
+	Connection    NetworkConnection `json:"connection"`
+	FirstSeen     time.Time         `json:"first_seen"`
+	Duration      time.Duration     `json:"duration"`                 // Time the connection was open, in nanoseconds (closed events only)
+	Preexisting   bool              `json:"preexisting,omitempty"`    // Open when the monitor started, so the duration is a lower bound
+	BytesSent     int64             `json:"bytes_sent,omitempty"`     // Bytes sent, when the capture source records them (MonitorNetworkConnections does not)
+	BytesReceived int64             `json:"bytes_received,omitempty"` // Bytes received, when the capture source records them
+	TimeWait      bool              `json:"time_wait,omitempty"`      // Only seen in TIME_WAIT: FirstSeen is when it was seen and the duration is unknown
+}
+
+// socketOwner is the last process seen using a local port and remote endpoint.
+type socketOwner struct {
+	connection NetworkConnection
+	lastSeen   time.Time
+}
+
+// socketOwnerLifetime is how long an owner is remembered for attributing TIME_WAIT sockets; TIME_WAIT lasts at most 4 minutes.
+const socketOwnerLifetime = 5 * time.Minute
+
+// trackedConnection is a connection seen by the monitor.
+type trackedConnection struct {
+	connection  NetworkConnection
+	firstSeen   time.Time
+	preexisting bool
+}
+
+// MonitorNetworkConnections polls the socket table and reports connections as they open and close.
+//
+// Description:
+// - Reads the socket table with GetSocketTable every interval and compares it with the previous poll.
+// - Emits an "opened" event for each new connection and a "closed" event, with the time it was open, for each connection that disappeared.
+// - Events carry the owning process from the poll where the connection was first seen, so closed events keep their attribution after the process exits.
+// - Connections are identified by protocol, endpoints and socket inode (or owning process on Windows); state changes do not produce events.
+// - By default only sockets with a remote endpoint are tracked. TIME_WAIT, CLOSE and orphaned sockets are ignored since they no longer belong to a process.
+// - Connections that open and close between two polls are not seen; durations are accurate to one interval.
+// - With CountTimeWait, such a connection is still reported by its TIME_WAIT socket as a "closed" event with TimeWait set and no duration.
+// - TIME_WAIT sockets are attributed to the last process seen with the same local port and remote endpoint, or else with the same remote endpoint.
+// - Connections closed first by the remote end leave no local TIME_WAIT socket, so they are not seen even with CountTimeWait.
+// - Runs until the context is cancelled. Errors from later polls are logged and the poll is skipped.
+//
+// Parameters:
+// - ctx (context.Context): Stops the monitor when cancelled.
+// - options (MonitorOptions): Poll interval, filter and which sockets to track.
+// - onEvent (func(ConnectionEvent)): Called for each event (use nil to log events as JSON).
+//
+// Returns:
+// - error: An error if the first poll of the socket table fails.
+//
+// Example Usage:
+// ```go
+// ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+// defer stop()
+//
+// filter, _ := ParseConnectionFilter("proto=tcp")
+// err := MonitorNetworkConnections(ctx, MonitorOptions{Interval: time.Second, Filter: filter}, WriteConnectionEventsNDJSON(os.Stdout))
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	}
+//
+// ```
+func MonitorNetworkConnections(ctx context.Context, options MonitorOptions, onEvent func(ConnectionEvent)) error {
+	if onEvent == nil {
+		onEvent = func(event ConnectionEvent) {
+			output, _ := json.Marshal(event)
+			log.Printf("CONNECTION %s", output)
+		}
+	}
+	if options.Interval <= 0 {
+		options.Interval = 2 * time.Second
+	}
+
+	sockets, err := GetSocketTable()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	owners := map[string]socketOwner{}
+	timeWait := map[string]bool{} // TIME_WAIT sockets seen in the previous poll, by endpoints
+	if options.CountTimeWait {
+		recordSocketOwners(owners, sockets, start)
+		for key := range timeWaitSockets(sockets) {
+			timeWait[key] = true
+		}
+	}
+	tracked := map[string]trackedConnection{}
+	for key, connection := range monitoredConnections(sockets, options) {
+		tracked[key] = trackedConnection{connection: connection, firstSeen: start, preexisting: !options.ReportExisting}
+		if options.ReportExisting {
+			onEvent(ConnectionEvent{Time: start, Event: "opened", Connection: connection, FirstSeen: start})
+		}
+	}
+
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		sockets, err := GetSocketTable()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			continue
+		}
+		now := time.Now()
+		current := monitoredConnections(sockets, options)
+
+		if options.CountTimeWait {
+			// The TIME_WAIT socket of a tracked connection is its normal close, not a new connection.
+			trackedEndpoints := map[string]bool{}
+			for _, previous := range tracked {
+				trackedEndpoints[endpointKey(previous.connection)] = true
+			}
+			recordSocketOwners(owners, sockets, now)
+			waiting := timeWaitSockets(sockets)
+			for key, socket := range waiting {
+				if timeWait[key] || trackedEndpoints[key] {
+					continue
+				}
+				socket = attributeTimeWait(socket, owners)
+				if options.Filter.Matches(socket) {
+					onEvent(ConnectionEvent{Time: now, Event: "closed", Connection: socket, FirstSeen: now, TimeWait: true})
+				}
+			}
+			timeWait = map[string]bool{}
+			for key := range waiting {
+				timeWait[key] = true
+			}
+		}
+
+		for key, previous := range tracked {
+			if _, open := current[key]; open {
+				continue
+			}
+			delete(tracked, key)
+			onEvent(ConnectionEvent{
+				Time:        now,
+				Event:       "closed",
+				Connection:  previous.connection,
+				FirstSeen:   previous.firstSeen,
+				Duration:    now.Sub(previous.firstSeen),
+				Preexisting: previous.preexisting,
+			})
+		}
+		for key, connection := range current {
+			if _, seen := tracked[key]; seen {
+				continue
+			}
+			tracked[key] = trackedConnection{connection: connection, firstSeen: now}
+			onEvent(ConnectionEvent{Time: now, Event: "opened", Connection: connection, FirstSeen: now})
+		}
+	}
+}
+
+// WriteConnectionEventsNDJSON returns an event handler that writes each event as one line of JSON.
+//
+// Description:
+// - The handler is safe to call from multiple goroutines; write errors are logged.
+// - The output can be read back with ReadConnectionEventsNDJSON.
+//
+// Parameters:
+// - writer (io.Writer): Where to write the events (e.g., os.Stdout or a capture file).
+//
+// Returns:
+// - func(ConnectionEvent): The handler to pass to MonitorNetworkConnections.
+//
+// Example Usage:
+// ```go
+// file, _ := os.Create("connections.ndjson")
+// defer file.Close()
+// err := MonitorNetworkConnections(ctx, MonitorOptions{}, WriteConnectionEventsNDJSON(file))
+// ```
+func WriteConnectionEventsNDJSON(writer io.Writer) func(ConnectionEvent) {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(writer)
+	return func(event ConnectionEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err := encoder.Encode(event); err != nil {
+			log.Printf("Error: failed to write event: %v", err)
+		}
+	}
+}
+
+// monitoredConnections keys the sockets the monitor tracks by their identity.
+func monitoredConnections(sockets []NetworkConnection, options MonitorOptions) map[string]NetworkConnection {
+	connections := map[string]NetworkConnection{}
+	for _, socket := range sockets {
+		if socket.State == "TIME_WAIT" || socket.State == "CLOSE" || socket.Inode == "0" {
+			continue // Closed by its process; the kernel is finishing the shutdown
+		}
+		if !options.IncludeListening && !hasRemoteEndpoint(socket) {
+			continue
+		}
+		if !options.Filter.Matches(socket) {
+			continue
+		}
+		connections[connectionKey(socket)] = socket
+	}
+	return connections
+}
+
+// hasRemoteEndpoint reports whether a socket is connected to a remote address.
+func hasRemoteEndpoint(socket NetworkConnection) bool {
+	if socket.Protocol == "unix" || socket.State == "LISTEN" || socket.State == "LISTENING" {
+		return false
+	}
+	switch socket.RemotePort {
+	case "", "0", "*":
+		return false
+	}
+	return true
+}
+
+// connectionKey identifies a connection across polls. The socket inode, when known, already tells apart
+// connections that reuse the same endpoints, and keeps the key stable if the inode is looked up in another process.
+func connectionKey(socket NetworkConnection) string {
+	owner := socket.ProcessId
+	if socket.Inode != "" && socket.Inode != "0" {
+		owner = "inode:" + socket.Inode
+	}
+	return strings.Join([]string{
+		socket.Protocol,
+		socket.LocalAddress, socket.LocalPort,
+		socket.RemoteAddress, socket.RemotePort,
+		owner,
+	}, "|")
+}
+
+// endpointKey identifies a socket by its protocol and endpoints alone, so a connection and its TIME_WAIT socket share it.
+func endpointKey(socket NetworkConnection) string {
+	return strings.Join([]string{socket.Protocol, socket.LocalAddress, socket.LocalPort, socket.RemoteAddress, socket.RemotePort}, "|")
+}
+
+// ownerKey identifies the sockets whose owner a TIME_WAIT socket is attributed to: by local port and remote endpoint,
+// or by remote endpoint alone when withLocalPort is false.
+func ownerKey(socket NetworkConnection, withLocalPort bool) string {
+	localPort := "*"
+	if withLocalPort {
+		localPort = socket.LocalPort
+	}
+	return strings.Join([]string{socket.Protocol, localPort, socket.RemoteAddress, socket.RemotePort}, "|")
+}
+
+// timeWaitSockets returns the TIME_WAIT sockets with a remote endpoint, by endpointKey.
+func timeWaitSockets(sockets []NetworkConnection) map[string]NetworkConnection {
+	waiting := map[string]NetworkConnection{}
+	for _, socket := range sockets {
+		if socket.State == "TIME_WAIT" && hasRemoteEndpoint(socket) {
+			waiting[endpointKey(socket)] = socket
+		}
+	}
+	return waiting
+}
+
+// recordSocketOwners remembers the process of every owned connected socket and forgets owners not seen for socketOwnerLifetime.
+func recordSocketOwners(owners map[string]socketOwner, sockets []NetworkConnection, now time.Time) {
+	for _, socket := range sockets {
+		if socket.State == "TIME_WAIT" || socket.ProcessId == "" || socket.ProcessId == "0" || !hasRemoteEndpoint(socket) {
+			continue
+		}
+		owners[ownerKey(socket, true)] = socketOwner{connection: socket, lastSeen: now}
+		owners[ownerKey(socket, false)] = socketOwner{connection: socket, lastSeen: now}
+	}
+	for key, owner := range owners {
+		if now.Sub(owner.lastSeen) > socketOwnerLifetime {
+			delete(owners, key)
+		}
+	}
+}
+
+// attributeTimeWait fills in the process of a TIME_WAIT socket from the last known owner of its local port and remote endpoint,
+// falling back to the last owner of the remote endpoint. Sockets with no known owner are returned unchanged.
+func attributeTimeWait(socket NetworkConnection, owners map[string]socketOwner) NetworkConnection {
+	owner, ok := owners[ownerKey(socket, true)]
+	if !ok {
+		owner, ok = owners[ownerKey(socket, false)]
+	}
+	if ok {
+		socket.ProcessId = owner.connection.ProcessId
+		socket.ProcessName = owner.connection.ProcessName
+	}
+	return socket
+}
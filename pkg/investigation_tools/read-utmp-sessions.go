@@ -0,0 +1,277 @@
+// This module is cross-platform (Windows and Linux).
+
+package investigation_tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"time"
+)
+
+// LogonSession represents a user logon session.
+type LogonSession struct {
+	UserName       string
+	LogonTime      time.Time
+	LogonType      string        // Windows logon type (e.g., "2" interactive, "10" remote interactive); on Linux "local", "remote" or "failed"
+	Terminal       string        // TTY or pseudo-terminal (e.g., "pts/0", "ssh:notty") (Linux only)
+	SourceHost     string        // Host name or address the user logged on from
+	SourceIP       string        // IP address the user logged on from, when recorded
+	LogoffTime     time.Time     // Zero while the session is open (Linux only)
+	Duration       time.Duration // Time from logon to logoff (Linux only)
+	Status         string        // "logged out", "still logged in", "down", "crash" or "failed" (Linux only)
+	FailedAttempts int           // Failed logons for the user from the same source since their previous logon (Linux only)
+}
+
+// UtmpRecord is one record of a utmp, wtmp or btmp file.
+type UtmpRecord struct {
+	Type    int // 1 RUN_LVL, 2 BOOT_TIME, 5 INIT_PROCESS, 6 LOGIN_PROCESS, 7 USER_PROCESS, 8 DEAD_PROCESS, ...
+	PID     int
+	Line    string // Device name without "/dev/" (e.g., "pts/0"), or "~" for boot and run-level records
+	ID      string
+	User    string
+	Host    string
+	Address string // Remote IP address, or "" if not recorded
+	Session int
+	Time    time.Time
+}
+
+// Record types of struct utmp.
+const (
+	utmpRunLevel     = 1
+	utmpBootTime     = 2
+	utmpLoginProcess = 6
+	utmpUserProcess  = 7
+	utmpDeadProcess  = 8
+	utmpAccounting   = 9
+)
+
+// utmpRecordSize is the size of struct utmp on every glibc architecture; 64-bit ABIs keep 32-bit times for compatibility.
+const utmpRecordSize = 384
+
+// ReadUtmpFile reads the records of a utmp, wtmp or btmp file.
+//
+// Description:
+// - Parses the glibc struct utmp format used by /var/run/utmp, /var/log/wtmp and /var/log/btmp.
+// - The byte order is detected from the records, so files copied from hosts of another architecture can be read.
+// - A partial record at the end of the file, as left by a copy taken while a record was being written, is ignored.
+//
+// Parameters:
+// - path (string): The file to read (e.g., "/var/log/wtmp" or a copy from another host).
+//
+// Returns:
+// - []UtmpRecord: The records, in file order.
+// - error: An error if the file cannot be read or does not contain utmp records.
+//
+// Example Usage:
+// ```go
+// records, err := ReadUtmpFile("/evidence/host1/wtmp")
+//
+//	if err != nil {
+//	    fmt.Println("Error:", err)
+//	} else {
+//
+//	    for _, record := range records {
+//	        fmt.Println(record.Time, record.Type, record.User, record.Line, record.Host)
+//	    }
+//	}
+//
+// ```
+func ReadUtmpFile(path string) ([]UtmpRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	data = data[:len(data)-len(data)%utmpRecordSize]
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	order := detectUtmpByteOrder(data)
+	if order == nil {
+		return nil, fmt.Errorf("%s does not contain utmp records", path)
+	}
+
+	var records []UtmpRecord
+	for offset := 0; offset < len(data); offset += utmpRecordSize {
+		// short ut_type; pid_t ut_pid; char ut_line[32]; char ut_id[4]; char ut_user[32]; char ut_host[256];
+		// struct exit_status ut_exit; int32 ut_session; struct { int32 tv_sec, tv_usec } ut_tv; int32 ut_addr_v6[4]; char unused[20]
+		record := data[offset : offset+utmpRecordSize]
+		recordType := int(int16(order.Uint16(record[0:])))
+		if recordType == 0 {
+			continue // EMPTY
+		}
+		seconds := int64(int32(order.Uint32(record[340:])))
+		microseconds := int64(int32(order.Uint32(record[344:])))
+		records = append(records, UtmpRecord{
+			Type:    recordType,
+			PID:     int(int32(order.Uint32(record[4:]))),
+			Line:    cString(record[8:40]),
+			ID:      cString(record[40:44]),
+			User:    cString(record[44:76]),
+			Host:    cString(record[76:332]),
+			Session: int(int32(order.Uint32(record[336:]))),
+			Time:    time.Unix(seconds, microseconds*1000),
+			Address: utmpAddress(record[348:364]),
+		})
+	}
+	return records, nil
+}
+
+// ReconstructLogonSessions pairs logon and logoff records into sessions.
+//
+// Description:
+// - A USER_PROCESS record opens a session on its terminal; the next DEAD_PROCESS record on that terminal closes it ("logged out").
+// - A shutdown run-level record closes every open session ("down"); a boot record closes the ones still open ("crash").
+// - Sessions still open at the end of the records are "still logged in"; on a copied file that only means no logoff was recorded.
+// - Each failure record becomes a session with status "failed", and successful sessions count the failures that preceded them.
+//
+// Parameters:
+// - records ([]UtmpRecord): Records of a wtmp (or utmp) file, in file order.
+// - failures ([]UtmpRecord): Records of a btmp file (use nil if unavailable).
+//
+// Returns:
+// - []LogonSession: The sessions and failed logons, sorted by logon time.
+//
+// Example Usage:
+// ```go
+// records, _ := ReadUtmpFile("/evidence/host1/wtmp")
+// failures, _ := ReadUtmpFile("/evidence/host1/btmp")
+// sessions := ReconstructLogonSessions(records, failures)
+// ```
+func ReconstructLogonSessions(records, failures []UtmpRecord) []LogonSession {
+	var sessions []LogonSession
+	open := map[string]int{} // Index of the open session on each terminal
+	closeSession := func(index int, at time.Time, status string) {
+		sessions[index].LogoffTime = at
+		sessions[index].Duration = at.Sub(sessions[index].LogonTime)
+		sessions[index].Status = status
+	}
+	closeAll := func(at time.Time, status string) {
+		for line, index := range open {
+			closeSession(index, at, status)
+			delete(open, line)
+		}
+	}
+
+	for _, record := range records {
+		switch record.Type {
+		case utmpUserProcess:
+			if record.User == "" {
+				continue
+			}
+			if index, ok := open[record.Line]; ok {
+				closeSession(index, record.Time, "logged out") // The logoff record was lost
+			}
+			open[record.Line] = len(sessions)
+			sessions = append(sessions, LogonSession{
+				UserName:   record.User,
+				LogonTime:  record.Time,
+				LogonType:  utmpLogonType(record.Host),
+				Terminal:   record.Line,
+				SourceHost: record.Host,
+				SourceIP:   record.Address,
+				Status:     "still logged in",
+			})
+		case utmpDeadProcess:
+			if index, ok := open[record.Line]; ok {
+				closeSession(index, record.Time, "logged out")
+				delete(open, record.Line)
+			}
+		case utmpRunLevel:
+			if record.User == "shutdown" {
+				closeAll(record.Time, "down")
+			}
+		case utmpBootTime:
+			closeAll(record.Time, "crash")
+		}
+	}
+
+	// Count the failures for each user and source between their successive successful logons.
+	failureTimes := map[string][]time.Time{}
+	for _, failure := range failures {
+		if failure.Type != utmpLoginProcess && failure.Type != utmpUserProcess {
+			continue
+		}
+		key := failure.User + "|" + failure.Host
+		failureTimes[key] = append(failureTimes[key], failure.Time)
+		sessions = append(sessions, LogonSession{
+			UserName:   failure.User,
+			LogonTime:  failure.Time,
+			LogonType:  "failed",
+			Terminal:   failure.Line,
+			SourceHost: failure.Host,
+			SourceIP:   failure.Address,
+			Status:     "failed",
+		})
+	}
+	for _, times := range failureTimes {
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].LogonTime.Before(sessions[j].LogonTime) })
+	previousLogon := map[string]time.Time{}
+	for i := range sessions {
+		if sessions[i].Status == "failed" {
+			continue
+		}
+		key := sessions[i].UserName + "|" + sessions[i].SourceHost
+		times := failureTimes[key]
+		from := sort.Search(len(times), func(j int) bool { return times[j].After(previousLogon[key]) })
+		to := sort.Search(len(times), func(j int) bool { return times[j].After(sessions[i].LogonTime) })
+		sessions[i].FailedAttempts = to - from
+		previousLogon[key] = sessions[i].LogonTime
+	}
+	return sessions
+}
+
+// detectUtmpByteOrder returns the byte order in which the most records have a valid type and timestamp, or nil if none do.
+func detectUtmpByteOrder(data []byte) binary.ByteOrder {
+	var best binary.ByteOrder
+	bestValid := 0
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		valid := 0
+		for offset := 0; offset < len(data); offset += utmpRecordSize {
+			recordType := int16(order.Uint16(data[offset:]))
+			seconds := int32(order.Uint32(data[offset+340:]))
+			microseconds := int32(order.Uint32(data[offset+344:]))
+			if recordType > 0 && recordType <= utmpAccounting && seconds > 0 && microseconds >= 0 && microseconds < 1000000 {
+				valid++
+			}
+		}
+		if valid > bestValid {
+			best, bestValid = order, valid
+		}
+	}
+	return best
+}
+
+// utmpAddress formats ut_addr_v6, which holds an IPv4 address in its first word or an IPv6 address, in network byte order.
+func utmpAddress(raw []byte) string {
+	if bytes.Equal(raw, make([]byte, 16)) {
+		return ""
+	}
+	if bytes.Equal(raw[4:], make([]byte, 12)) {
+		return netip.AddrFrom4([4]byte(raw[:4])).String()
+	}
+	return netip.AddrFrom16([16]byte(raw)).String()
+}
+
+// utmpLogonType classifies a session by its host field; X displays such as ":0" are local.
+func utmpLogonType(host string) string {
+	if host == "" || host[0] == ':' {
+		return "local"
+	}
+	return "remote"
+}
+
+// cString returns the text of a NUL-padded field, which has no terminator when the text fills it.
+func cString(field []byte) string {
+	if end := bytes.IndexByte(field, 0); end >= 0 {
+		field = field[:end]
+	}
+	return string(field)
+}
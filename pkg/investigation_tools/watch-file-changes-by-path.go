@@ -36,12 +36,7 @@ import (
 // ```
 func WatchFileChangesByPath(path string) error {
 	// Normalize the path for cross-platform compatibility
-	normalizedPath := filepath.Clean(path)
-	if runtime.GOOS == "windows" {
-		normalizedPath = strings.ReplaceAll(normalizedPath, "/", "\\")
-	} else {
-		normalizedPath = strings.ReplaceAll(normalizedPath, "\\", "/")
-	}
+	normalizedPath := normalizeWatchPath(path)
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -50,18 +45,7 @@ func WatchFileChangesByPath(path string) error {
 	defer watcher.Close()
 
 	// Add the specified path and its subdirectories to the watcher
-	err = filepath.Walk(normalizedPath, func(p string, info os.FileInfo, err error) error {
-		if err != nil {
-			return fmt.Errorf("error accessing path '%s': %v", p, err)
-		}
-		if info.IsDir() {
-			if err := watcher.Add(p); err != nil {
-				return fmt.Errorf("failed to add path '%s' to watcher: %v", p, err)
-			}
-		}
-		return nil
-	})
-	if err != nil {
+	if err := addWatchTree(watcher, normalizedPath); err != nil {
 		return err
 	}
 
@@ -73,12 +57,7 @@ func WatchFileChangesByPath(path string) error {
 				return nil
 			}
 			// Normalize event paths for cross-platform consistency
-			eventName := filepath.Clean(event.Name)
-			if runtime.GOOS == "windows" {
-				eventName = strings.ReplaceAll(eventName, "/", "\\")
-			} else {
-				eventName = strings.ReplaceAll(eventName, "\\", "/")
-			}
+			eventName := normalizeWatchPath(event.Name)
 
 			switch {
 			case event.Op&fsnotify.Create == fsnotify.Create:
@@ -98,3 +77,27 @@ func WatchFileChangesByPath(path string) error {
 		}
 	}
 }
+
+// normalizeWatchPath cleans a path and uses the platform's path separator.
+func normalizeWatchPath(path string) string {
+	normalized := filepath.Clean(path)
+	if runtime.GOOS == "windows" {
+		return strings.ReplaceAll(normalized, "/", "\\")
+	}
+	return strings.ReplaceAll(normalized, "\\", "/")
+}
+
+// addWatchTree adds a directory and all of its subdirectories to a watcher.
+func addWatchTree(watcher *fsnotify.Watcher, root string) error {
+	return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("error accessing path '%s': %v", p, err)
+		}
+		if info.IsDir() {
+			if err := watcher.Add(p); err != nil {
+				return fmt.Errorf("failed to add path '%s' to watcher: %v", p, err)
+			}
+		}
+		return nil
+	})
+}